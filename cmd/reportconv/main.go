@@ -0,0 +1,389 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/urfave/cli/v3"
+
+	"github.com/LeipzigeSports/les-report-md-to-pdf/internal/cache"
+	"github.com/LeipzigeSports/les-report-md-to-pdf/internal/config"
+	"github.com/LeipzigeSports/les-report-md-to-pdf/internal/i18n"
+	"github.com/LeipzigeSports/les-report-md-to-pdf/internal/jobs"
+	"github.com/LeipzigeSports/les-report-md-to-pdf/internal/middleware"
+	"github.com/LeipzigeSports/les-report-md-to-pdf/internal/pandoc"
+	"github.com/LeipzigeSports/les-report-md-to-pdf/internal/server"
+	"github.com/LeipzigeSports/les-report-md-to-pdf/internal/store"
+)
+
+// splitLanguages parses a comma-separated --languages flag value into its component tags.
+func splitLanguages(s string) []string {
+	parts := strings.Split(s, ",")
+	langs := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if p = strings.TrimSpace(p); p != "" {
+			langs = append(langs, p)
+		}
+	}
+	return langs
+}
+
+func resolveAppRoot(appRoot string) string {
+	if appRoot != "" {
+		return appRoot
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		log.Fatalf("failed to determine working directory: %v", err)
+	}
+
+	log.Printf("no application root provided, using working directory at %v\n", cwd)
+	return cwd
+}
+
+func setUpLogging(appRoot string) func() {
+	err := os.Mkdir(filepath.Join(appRoot, "logs"), 0750) // rwx-r-x---
+	if err != nil && !os.IsExist(err) {
+		log.Fatalf("failed to create log directory: %v", err)
+	}
+
+	// rdwr = open read-write, create = create if not exist, append = append when writing
+	f, err := os.OpenFile(filepath.Join(appRoot, "logs", "les-reportconv.log"), os.O_RDWR|os.O_CREATE|os.O_APPEND, 0660)
+	if err != nil {
+		log.Fatalf("failed to create log file: %v", err)
+	}
+
+	logWriter := io.MultiWriter(os.Stderr, f)
+	log.SetOutput(logWriter)
+
+	return func() {
+		if err := f.Close(); err != nil {
+			log.Printf("failed to close log file: %v\n", err)
+		}
+	}
+}
+
+func handleServe(ctx context.Context, cmd *cli.Command) error {
+	appRoot := cmd.String("applicationRoot")
+	pandocExecutable := cmd.String("pandocExecutable")
+	pandocTimeout := cmd.Duration("pandocTimeout")
+	host := cmd.String("host")
+	port := cmd.Int("port")
+	archiveDir := cmd.String("archiveDir")
+	archiveTTL := cmd.Duration("archiveTTL")
+	workers := cmd.Int("workers")
+	queueSize := cmd.Int("queueSize")
+	languages := splitLanguages(cmd.String("languages"))
+	requireAuth := cmd.Bool("requireAuth")
+	jwtSecret := cmd.String("jwtSecret")
+	cspConfigPath := cmd.String("cspConfig")
+	cacheDir := cmd.String("cacheDir")
+	cacheMaxBytes := cmd.Int64("cacheMaxBytes")
+	cacheMaxEntries := cmd.Int("cacheMaxEntries")
+
+	if port <= 0 {
+		return fmt.Errorf("port must be a positive number, is: %v", port)
+	}
+
+	if workers <= 0 {
+		return fmt.Errorf("workers must be a positive number, is: %v", workers)
+	}
+
+	if requireAuth && jwtSecret == "" {
+		return fmt.Errorf("jwtSecret is required when requireAuth is set")
+	}
+
+	appRoot = resolveAppRoot(appRoot)
+
+	closeLog := setUpLogging(appRoot)
+	defer closeLog()
+
+	var st *store.Store
+
+	if archiveDir != "" {
+		var err error
+		st, err = store.New(archiveDir, archiveTTL)
+		if err != nil {
+			return fmt.Errorf("failed to set up report archive: %w", err)
+		}
+
+		go st.RunJanitor(ctx)
+	}
+
+	cfg := config.New(appRoot, pandocExecutable, pandocTimeout, host, port)
+
+	reg, err := i18n.New(cfg.ResourcesDir, languages)
+	if err != nil {
+		return fmt.Errorf("failed to set up language registry: %w", err)
+	}
+
+	secHeaders, err := middleware.LoadSecurityHeaders(cspConfigPath)
+	if err != nil {
+		return fmt.Errorf("failed to set up security headers: %w", err)
+	}
+
+	var cch *cache.Cache
+	var pandocVersion string
+
+	if cacheDir != "" {
+		pandocVersion, err = pandoc.Version(ctx, pandocExecutable)
+		if err != nil {
+			return fmt.Errorf("failed to determine pandoc version: %w", err)
+		}
+
+		cch, err = cache.New(cacheDir, cacheMaxBytes, cacheMaxEntries)
+		if err != nil {
+			return fmt.Errorf("failed to set up PDF cache: %w", err)
+		}
+	}
+
+	conv := pandoc.NewConverter(cfg.PandocExecutable, cfg.PandocFontsPath, cfg.PandocTimeout)
+	pool := jobs.NewPool(conv, st, cch, pandocVersion, workers, queueSize)
+	pool.Start(ctx)
+	go pool.RunJanitor(ctx)
+
+	server.New(cfg, st, pool, reg, cch, pandocVersion, secHeaders, requireAuth, jwtSecret).Run(ctx)
+
+	return nil
+}
+
+func handleDev(ctx context.Context, cmd *cli.Command) error {
+	mdPath := cmd.String("mdFile")
+	if mdPath == "" {
+		return fmt.Errorf("mdFile is required")
+	}
+
+	appRoot := resolveAppRoot(cmd.String("applicationRoot"))
+	pandocExecutable := cmd.String("pandocExecutable")
+	pandocTimeout := cmd.Duration("pandocTimeout")
+	team := cmd.String("team")
+	lang := cmd.String("lang")
+	host := cmd.String("host")
+	port := cmd.Int("port")
+	languages := splitLanguages(cmd.String("languages"))
+
+	if port <= 0 {
+		return fmt.Errorf("port must be a positive number, is: %v", port)
+	}
+
+	resourcesDir := filepath.Join(appRoot, config.ResourcesDirName)
+	fontsPath := filepath.Join(resourcesDir, config.PandocFontsSubPath)
+
+	reg, err := i18n.New(resourcesDir, languages)
+	if err != nil {
+		return fmt.Errorf("failed to set up language registry: %w", err)
+	}
+
+	teamName, err := reg.TeamName(lang, team)
+	if err != nil {
+		return fmt.Errorf("failed to resolve team name: %w", err)
+	}
+
+	templatePath := reg.TemplatePath(lang)
+	conv := pandoc.NewConverter(pandocExecutable, fontsPath, pandocTimeout)
+
+	return server.RunDev(ctx, server.DevOptions{
+		MdPath:       mdPath,
+		WatchDirs:    []string{filepath.Dir(templatePath), fontsPath},
+		Team:         teamName,
+		Lang:         lang,
+		TemplatePath: templatePath,
+		Host:         host,
+		Port:         port,
+		Converter:    conv,
+	})
+}
+
+func main() {
+	err := godotenv.Load()
+	if err != nil {
+		log.Printf("failed to load .env file: %v\n", err)
+	}
+
+	appRootFlag := &cli.StringFlag{
+		Name:    "applicationRoot",
+		Aliases: []string{"appRoot"},
+		Usage:   "path to application root directory",
+		Sources: cli.EnvVars("APPLICATION_ROOT"),
+	}
+
+	pandocExecutableFlag := &cli.StringFlag{
+		Name:    "pandocExecutable",
+		Usage:   "name of pandoc executable",
+		Value:   "pandoc",
+		Sources: cli.EnvVars("PANDOC_EXECUTABLE"),
+	}
+
+	pandocTimeoutFlag := &cli.DurationFlag{
+		Name:    "pandocTimeout",
+		Usage:   "timeout for pandoc conversion",
+		Value:   10 * time.Second,
+		Sources: cli.EnvVars("PANDOC_TIMEOUT"),
+	}
+
+	hostFlag := &cli.StringFlag{
+		Name:    "host",
+		Usage:   "host to expose service on",
+		Aliases: []string{"h"},
+		Value:   "0.0.0.0",
+		Sources: cli.EnvVars("HTTP_HOST"),
+	}
+
+	portFlag := &cli.IntFlag{
+		Name:    "port",
+		Usage:   "port to expose service on",
+		Aliases: []string{"p"},
+		Value:   3333,
+		Sources: cli.EnvVars("HTTP_PORT"),
+	}
+
+	archiveDirFlag := &cli.StringFlag{
+		Name:    "archiveDir",
+		Usage:   "directory to persist converted reports in for later retrieval; leave unset to disable the archive",
+		Sources: cli.EnvVars("ARCHIVE_DIR"),
+	}
+
+	archiveTTLFlag := &cli.DurationFlag{
+		Name:    "archiveTTL",
+		Usage:   "how long an archived report stays retrievable before the janitor sweeps it",
+		Value:   7 * 24 * time.Hour,
+		Sources: cli.EnvVars("ARCHIVE_TTL"),
+	}
+
+	workersFlag := &cli.IntFlag{
+		Name:    "workers",
+		Usage:   "number of concurrent pandoc conversions the job queue runs",
+		Value:   2,
+		Sources: cli.EnvVars("WORKERS"),
+	}
+
+	queueSizeFlag := &cli.IntFlag{
+		Name:    "queueSize",
+		Usage:   "number of queued conversion jobs allowed before submissions are rejected with 503",
+		Value:   16,
+		Sources: cli.EnvVars("QUEUE_SIZE"),
+	}
+
+	languagesFlag := &cli.StringFlag{
+		Name:    "languages",
+		Usage:   "comma-separated list of supported language tags; the first is used as the fallback",
+		Value:   "de,en",
+		Sources: cli.EnvVars("LANGUAGES"),
+	}
+
+	requireAuthFlag := &cli.BoolFlag{
+		Name:    "requireAuth",
+		Usage:   "require a JWT bearer token on POST / and other write routes",
+		Sources: cli.EnvVars("REQUIRE_AUTH"),
+	}
+
+	jwtSecretFlag := &cli.StringFlag{
+		Name:    "jwtSecret",
+		Usage:   "HS256 secret used to verify JWT bearer tokens when requireAuth is set",
+		Sources: cli.EnvVars("JWT_SECRET"),
+	}
+
+	cspConfigFlag := &cli.StringFlag{
+		Name:    "cspConfig",
+		Usage:   "path to a TOML file overriding the default security headers; leave unset to use the built-in defaults",
+		Sources: cli.EnvVars("CSP_CONFIG"),
+	}
+
+	cacheDirFlag := &cli.StringFlag{
+		Name:    "cacheDir",
+		Usage:   "directory to cache rendered PDFs in, keyed by content digest; leave unset to disable the cache",
+		Sources: cli.EnvVars("CACHE_DIR"),
+	}
+
+	cacheMaxBytesFlag := &cli.Int64Flag{
+		Name:    "cacheMaxBytes",
+		Usage:   "total size the PDF cache may grow to before it evicts least-recently-used entries",
+		Value:   1 << 30, // 1 GiB
+		Sources: cli.EnvVars("CACHE_MAX_BYTES"),
+	}
+
+	cacheMaxEntriesFlag := &cli.IntFlag{
+		Name:    "cacheMaxEntries",
+		Usage:   "maximum number of PDFs the cache may hold before it evicts least-recently-used entries",
+		Value:   500,
+		Sources: cli.EnvVars("CACHE_MAX_ENTRIES"),
+	}
+
+	cmd := &cli.Command{
+		Name:   "reportconv",
+		Usage:  "minimal server for converting Markdown reports to neat PDFs",
+		Action: handleServe,
+		Flags: []cli.Flag{
+			appRootFlag,
+			pandocExecutableFlag,
+			pandocTimeoutFlag,
+			hostFlag,
+			portFlag,
+			archiveDirFlag,
+			archiveTTLFlag,
+			workersFlag,
+			queueSizeFlag,
+			languagesFlag,
+			requireAuthFlag,
+			jwtSecretFlag,
+			cspConfigFlag,
+			cacheDirFlag,
+			cacheMaxBytesFlag,
+			cacheMaxEntriesFlag,
+		},
+		Commands: []*cli.Command{
+			{
+				Name:   "dev",
+				Usage:  "watch a Markdown file and live-preview the rendered PDF in a browser",
+				Action: handleDev,
+				Flags: []cli.Flag{
+					appRootFlag,
+					pandocExecutableFlag,
+					pandocTimeoutFlag,
+					languagesFlag,
+					&cli.StringFlag{
+						Name:     "mdFile",
+						Usage:    "path to the Markdown file to watch and preview",
+						Required: true,
+					},
+					&cli.StringFlag{
+						Name:  "team",
+						Usage: "team identifier to render the preview for",
+						Value: "team-esm",
+					},
+					&cli.StringFlag{
+						Name:  "lang",
+						Usage: "language tag to render the preview in",
+						Value: "de",
+					},
+					&cli.StringFlag{
+						Name:    "host",
+						Usage:   "host to expose the preview server on",
+						Aliases: []string{"h"},
+						Value:   "127.0.0.1",
+						Sources: cli.EnvVars("HTTP_HOST"),
+					},
+					&cli.IntFlag{
+						Name:    "port",
+						Usage:   "port to expose the preview server on",
+						Aliases: []string{"p"},
+						Value:   3334,
+						Sources: cli.EnvVars("HTTP_PORT"),
+					},
+				},
+			},
+		},
+	}
+
+	if err := cmd.Run(context.Background(), os.Args); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,268 @@
+// Package cache implements an on-disk, content-addressed LRU for rendered PDFs, so repeated
+// conversions of the same input are served directly from disk instead of re-invoking pandoc.
+package cache
+
+import (
+	"container/list"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// tmpSubdir holds in-progress pandoc output before it's adopted into the cache. Building output
+// files here (see Cache.TempDir) guarantees they share a filesystem with the cache directory, so
+// insert's adoption can be a plain, atomic rename instead of a cross-device copy.
+const tmpSubdir = "tmp"
+
+// entry is one cached PDF's bookkeeping. path is absolute; size is the file size in bytes.
+type entry struct {
+	key  string
+	path string
+	size int64
+}
+
+// Cache is an on-disk LRU of rendered PDFs, keyed by the content digest computed by Key. It is
+// safe for concurrent use.
+type Cache struct {
+	dir        string
+	maxBytes   int64
+	maxEntries int
+
+	sg singleflight.Group
+
+	mu         sync.Mutex
+	order      *list.List // front = most recently used
+	entries    map[string]*list.Element
+	totalBytes int64
+}
+
+// New opens dir as a cache root (creating it if necessary), bounded by maxBytes and maxEntries,
+// rebuilding its LRU index from whatever PDFs are already on disk.
+func New(dir string, maxBytes int64, maxEntries int) (*Cache, error) {
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Join(dir, tmpSubdir), 0750); err != nil {
+		return nil, fmt.Errorf("failed to create cache temp directory: %w", err)
+	}
+
+	c := &Cache{
+		dir:        dir,
+		maxBytes:   maxBytes,
+		maxEntries: maxEntries,
+		order:      list.New(),
+		entries:    make(map[string]*list.Element),
+	}
+
+	if err := c.loadExisting(); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// TempDir returns the directory in which callers should create the temporary pandoc output file
+// they pass to Render's build function. Building it here rather than in the OS default temp
+// directory guarantees it shares a filesystem with the cache, which --cacheDir commonly doesn't
+// with /tmp, so insert's adoption of it never hits a cross-device rename.
+func (c *Cache) TempDir() string {
+	return filepath.Join(c.dir, tmpSubdir)
+}
+
+func (c *Cache) loadExisting() error {
+	files, err := os.ReadDir(c.dir)
+	if err != nil {
+		return fmt.Errorf("failed to list cache directory: %w", err)
+	}
+
+	for _, f := range files {
+		if f.IsDir() || !strings.HasSuffix(f.Name(), ".pdf") {
+			continue
+		}
+
+		info, err := f.Info()
+		if err != nil {
+			continue
+		}
+
+		e := &entry{
+			key:  strings.TrimSuffix(f.Name(), ".pdf"),
+			path: filepath.Join(c.dir, f.Name()),
+			size: info.Size(),
+		}
+		c.entries[e.key] = c.order.PushBack(e)
+		c.totalBytes += e.size
+	}
+
+	c.evictLocked()
+	return nil
+}
+
+// path returns the cached PDF's path for key and marks it most-recently-used, or ok=false if
+// nothing is cached for key.
+func (c *Cache) path(key string) (path string, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[key]
+	if !ok {
+		return "", false
+	}
+
+	c.order.MoveToFront(el)
+	return el.Value.(*entry).path, true
+}
+
+// insert atomically adopts srcPath (typically a temporary pandoc output file, ideally created
+// under TempDir) as the cached PDF for key, evicting least-recently-used entries if the cache now
+// exceeds its limits. If srcPath isn't on the same filesystem as the cache directory, adoption
+// falls back to a copy-then-remove. Either way, srcPath is gone (moved, copied-and-removed, or
+// cleaned up on error) by the time insert returns.
+func (c *Cache) insert(key, srcPath string) (path string, err error) {
+	destPath := filepath.Join(c.dir, key+".pdf")
+
+	info, err := os.Stat(srcPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to stat cache source file: %w", err)
+	}
+
+	if err := adopt(srcPath, destPath); err != nil {
+		os.Remove(srcPath)
+		return "", fmt.Errorf("failed to move file into cache: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[key]; ok {
+		c.totalBytes -= el.Value.(*entry).size
+		c.order.Remove(el)
+	}
+
+	e := &entry{key: key, path: destPath, size: info.Size()}
+	c.entries[key] = c.order.PushFront(e)
+	c.totalBytes += e.size
+
+	c.evictLocked()
+
+	return destPath, nil
+}
+
+// adopt moves srcPath to destPath. It tries a plain rename first; if the two paths are on
+// different filesystems (os.Rename fails with EXDEV, e.g. because srcPath wasn't created under a
+// Cache's TempDir), it falls back to copying srcPath to destPath and then removing srcPath.
+func adopt(srcPath, destPath string) error {
+	err := os.Rename(srcPath, destPath)
+	if err == nil {
+		return nil
+	}
+
+	var linkErr *os.LinkError
+	if !errors.As(err, &linkErr) || !errors.Is(linkErr.Err, syscall.EXDEV) {
+		return err
+	}
+
+	src, err := os.Open(srcPath)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	tmp, err := os.CreateTemp(filepath.Dir(destPath), ".copy-*")
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(tmp, src); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	if err := os.Rename(tmp.Name(), destPath); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	return os.Remove(srcPath)
+}
+
+// evictLocked removes least-recently-used entries until the cache satisfies maxBytes and
+// maxEntries. The caller must hold c.mu.
+func (c *Cache) evictLocked() {
+	for c.totalBytes > c.maxBytes || c.order.Len() > c.maxEntries {
+		oldest := c.order.Back()
+		if oldest == nil {
+			break
+		}
+
+		e := oldest.Value.(*entry)
+		c.order.Remove(oldest)
+		delete(c.entries, e.key)
+		c.totalBytes -= e.size
+
+		if err := os.Remove(e.path); err != nil && !os.IsNotExist(err) {
+			log.Printf("failed to remove evicted cache entry %v: %v\n", e.key, err)
+		}
+	}
+}
+
+// Render returns the cached PDF's path for key, hit=true, if one exists. On a miss it calls
+// build to produce a PDF, adopts the result into the cache, and returns its new on-disk path with
+// hit=false. Concurrent Render calls for the same key share a single build call.
+func (c *Cache) Render(key string, build func() (pdfPath string, err error)) (path string, hit bool, err error) {
+	if path, ok := c.path(key); ok {
+		return path, true, nil
+	}
+
+	v, err, _ := c.sg.Do(key, func() (interface{}, error) {
+		if path, ok := c.path(key); ok {
+			return path, nil
+		}
+
+		tmpPath, err := build()
+		if err != nil {
+			return nil, err
+		}
+
+		return c.insert(key, tmpPath)
+	})
+	if err != nil {
+		return "", false, err
+	}
+
+	return v.(string), false, nil
+}
+
+// Clear removes every cached PDF from disk and empties the index.
+func (c *Cache) Clear() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for el := c.order.Front(); el != nil; el = el.Next() {
+		e := el.Value.(*entry)
+		if err := os.Remove(e.path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove cache entry %v: %w", e.key, err)
+		}
+	}
+
+	c.order.Init()
+	c.entries = make(map[string]*list.Element)
+	c.totalBytes = 0
+
+	return nil
+}
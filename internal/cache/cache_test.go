@@ -0,0 +1,221 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func newTestCache(t *testing.T, maxBytes int64, maxEntries int) *Cache {
+	t.Helper()
+
+	c, err := New(t.TempDir(), maxBytes, maxEntries)
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+
+	return c
+}
+
+// writeBuild returns a Render build function that writes contents to a fresh file under c's
+// TempDir and returns its path, mimicking how Pool.run produces pandoc output.
+func writeBuild(t *testing.T, c *Cache, contents string) func() (string, error) {
+	t.Helper()
+
+	return func() (string, error) {
+		f, err := os.CreateTemp(c.TempDir(), "test-output-")
+		if err != nil {
+			return "", err
+		}
+		if _, err := f.WriteString(contents); err != nil {
+			f.Close()
+			return "", err
+		}
+		f.Close()
+		return f.Name(), nil
+	}
+}
+
+func TestRenderMissThenHit(t *testing.T) {
+	c := newTestCache(t, 1<<20, 10)
+
+	builds := 0
+	build := func() (string, error) {
+		builds++
+		return writeBuild(t, c, "pdf-bytes")()
+	}
+
+	path1, hit1, err := c.Render("key-a", build)
+	if err != nil {
+		t.Fatalf("Render returned error: %v", err)
+	}
+	if hit1 {
+		t.Fatalf("expected a miss on first Render")
+	}
+
+	path2, hit2, err := c.Render("key-a", build)
+	if err != nil {
+		t.Fatalf("Render returned error: %v", err)
+	}
+	if !hit2 {
+		t.Fatalf("expected a hit on second Render for the same key")
+	}
+	if path1 != path2 {
+		t.Fatalf("hit returned a different path: %v, want %v", path2, path1)
+	}
+	if builds != 1 {
+		t.Fatalf("build was called %v times, want 1", builds)
+	}
+}
+
+func TestRenderCoalescesConcurrentBuilds(t *testing.T) {
+	c := newTestCache(t, 1<<20, 10)
+
+	var builds atomic.Int32
+	release := make(chan struct{})
+
+	build := func() (string, error) {
+		builds.Add(1)
+		<-release
+		return writeBuild(t, c, "pdf-bytes")()
+	}
+
+	const concurrency = 8
+	var wg sync.WaitGroup
+	paths := make([]string, concurrency)
+
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			path, _, err := c.Render("shared-key", build)
+			if err != nil {
+				t.Errorf("Render returned error: %v", err)
+				return
+			}
+			paths[i] = path
+		}(i)
+	}
+
+	close(release)
+	wg.Wait()
+
+	if got := builds.Load(); got != 1 {
+		t.Fatalf("build was called %v times for concurrent Render calls on the same key, want 1", got)
+	}
+
+	for i, p := range paths {
+		if p != paths[0] {
+			t.Fatalf("caller %v got path %v, want %v", i, p, paths[0])
+		}
+	}
+}
+
+func TestEvictionByMaxEntries(t *testing.T) {
+	c := newTestCache(t, 1<<20, 2)
+
+	pathA, _, err := c.Render("key-a", writeBuild(t, c, "aaa"))
+	if err != nil {
+		t.Fatalf("Render returned error: %v", err)
+	}
+	if _, _, err := c.Render("key-b", writeBuild(t, c, "bbb")); err != nil {
+		t.Fatalf("Render returned error: %v", err)
+	}
+	if _, _, err := c.Render("key-c", writeBuild(t, c, "ccc")); err != nil {
+		t.Fatalf("Render returned error: %v", err)
+	}
+
+	// key-a was the least recently used of the three once key-c was inserted, and maxEntries is
+	// 2, so it should have been evicted from both the index and disk.
+	if _, ok := c.path("key-a"); ok {
+		t.Fatalf("expected key-a to be evicted")
+	}
+	if _, err := os.Stat(pathA); !os.IsNotExist(err) {
+		t.Fatalf("expected key-a's file to be removed from disk, stat err = %v", err)
+	}
+
+	if _, ok := c.path("key-b"); !ok {
+		t.Fatalf("expected key-b to survive eviction")
+	}
+	if _, ok := c.path("key-c"); !ok {
+		t.Fatalf("expected key-c to survive eviction")
+	}
+}
+
+func TestRenderTouchesRecencyOnHit(t *testing.T) {
+	c := newTestCache(t, 1<<20, 2)
+
+	if _, _, err := c.Render("key-a", writeBuild(t, c, "aaa")); err != nil {
+		t.Fatalf("Render returned error: %v", err)
+	}
+	if _, _, err := c.Render("key-b", writeBuild(t, c, "bbb")); err != nil {
+		t.Fatalf("Render returned error: %v", err)
+	}
+
+	// Touch key-a again so it's now more recently used than key-b.
+	if _, hit, err := c.Render("key-a", writeBuild(t, c, "aaa")); err != nil || !hit {
+		t.Fatalf("expected a cache hit for key-a, hit=%v err=%v", hit, err)
+	}
+
+	if _, _, err := c.Render("key-c", writeBuild(t, c, "ccc")); err != nil {
+		t.Fatalf("Render returned error: %v", err)
+	}
+
+	if _, ok := c.path("key-b"); ok {
+		t.Fatalf("expected key-b, now the least recently used, to be evicted")
+	}
+	if _, ok := c.path("key-a"); !ok {
+		t.Fatalf("expected key-a to survive eviction after being re-touched")
+	}
+}
+
+func TestLoadExistingRebuildsIndexFromDisk(t *testing.T) {
+	dir := t.TempDir()
+
+	c1 := newCacheAt(t, dir, 1<<20, 10)
+	if _, _, err := c1.Render("key-a", writeBuild(t, c1, "aaa")); err != nil {
+		t.Fatalf("Render returned error: %v", err)
+	}
+
+	c2 := newCacheAt(t, dir, 1<<20, 10)
+	path, ok := c2.path("key-a")
+	if !ok {
+		t.Fatalf("expected a fresh Cache over the same directory to recover key-a from disk")
+	}
+	if filepath.Dir(path) != dir {
+		t.Fatalf("recovered entry path %v not under cache dir %v", path, dir)
+	}
+}
+
+func newCacheAt(t *testing.T, dir string, maxBytes int64, maxEntries int) *Cache {
+	t.Helper()
+
+	c, err := New(dir, maxBytes, maxEntries)
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+
+	return c
+}
+
+func TestClearRemovesAllEntries(t *testing.T) {
+	c := newTestCache(t, 1<<20, 10)
+
+	path, _, err := c.Render("key-a", writeBuild(t, c, "aaa"))
+	if err != nil {
+		t.Fatalf("Render returned error: %v", err)
+	}
+
+	if err := c.Clear(); err != nil {
+		t.Fatalf("Clear returned error: %v", err)
+	}
+
+	if _, ok := c.path("key-a"); ok {
+		t.Fatalf("expected key-a to be gone after Clear")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("expected key-a's file to be removed from disk after Clear, stat err = %v", err)
+	}
+}
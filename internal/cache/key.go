@@ -0,0 +1,29 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+)
+
+// Key computes the content-addressed cache key for a conversion: a SHA-256 over the Markdown
+// source, the team ID, the typst template's and font directory's modification times, and the
+// pandoc version string. Two requests that would render identically share a key.
+func Key(mdBytes []byte, teamID, templatePath, fontsDir, pandocVersion string) (string, error) {
+	templateInfo, err := os.Stat(templatePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to stat template: %w", err)
+	}
+
+	fontsInfo, err := os.Stat(fontsDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to stat fonts directory: %w", err)
+	}
+
+	h := sha256.New()
+	h.Write(mdBytes)
+	fmt.Fprintf(h, "\x00%s\x00%s\x00%s\x00%s", teamID, templateInfo.ModTime().UTC(), fontsInfo.ModTime().UTC(), pandocVersion)
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,44 @@
+// Package config holds the resolved application settings shared by the server and dev commands.
+package config
+
+import (
+	"path/filepath"
+	"time"
+)
+
+const (
+	ResourcesDirName           = "resources"
+	IndexSubPath               = "static/index.html"
+	PandocFontsSubPath         = "pandoc/fonts"
+	PandocTypstTemplateSubPath = "pandoc/templates/typst.template"
+)
+
+// Config carries the paths and tunables derived from CLI flags and environment variables.
+type Config struct {
+	AppRoot                 string
+	ResourcesDir            string
+	PandocFontsPath         string
+	PandocTypstTemplatePath string
+	IndexPath               string
+	PandocExecutable        string
+	PandocTimeout           time.Duration
+	Port                    int
+	Host                    string
+}
+
+// New resolves the resource paths rooted at appRoot and returns a ready-to-use Config.
+func New(appRoot, pandocExecutable string, pandocTimeout time.Duration, host string, port int) Config {
+	resourcesDir := filepath.Join(appRoot, ResourcesDirName)
+
+	return Config{
+		AppRoot:                 appRoot,
+		ResourcesDir:            resourcesDir,
+		PandocExecutable:        pandocExecutable,
+		PandocTimeout:           pandocTimeout,
+		Port:                    port,
+		Host:                    host,
+		PandocFontsPath:         filepath.Join(resourcesDir, PandocFontsSubPath),
+		PandocTypstTemplatePath: filepath.Join(resourcesDir, PandocTypstTemplateSubPath),
+		IndexPath:               filepath.Join(resourcesDir, IndexSubPath),
+	}
+}
@@ -0,0 +1,120 @@
+// Package i18n resolves the language of an incoming request and loads the localized team name
+// registry and typst template that go with it.
+package i18n
+
+import (
+	"fmt"
+	"net/http"
+	"path/filepath"
+
+	"github.com/BurntSushi/toml"
+	"golang.org/x/text/language"
+)
+
+// teamNamesDoc mirrors the shape of a resources/i18n/{lang}.toml file.
+type teamNamesDoc struct {
+	Teams map[string]string `toml:"teams"`
+}
+
+// Registry resolves a request's language against a configured set of supported tags and loads
+// each language's team name translations from resourcesDir/i18n/{lang}.toml. Typst templates are
+// expected at resourcesDir/pandoc/templates/{lang}/typst.template.
+type Registry struct {
+	resourcesDir string
+	supported    []string
+	matcher      language.Matcher
+	teamNames    map[string]map[string]string // lang -> teamID -> localized name
+}
+
+// New builds a Registry for the given supported language tags (e.g. "de", "en"), loading each
+// language's team name translations from resourcesDir. The first tag is used as the fallback
+// when no better match can be made.
+func New(resourcesDir string, supported []string) (*Registry, error) {
+	if len(supported) == 0 {
+		return nil, fmt.Errorf("at least one supported language is required")
+	}
+
+	tags := make([]language.Tag, 0, len(supported))
+	teamNames := make(map[string]map[string]string, len(supported))
+
+	for _, lang := range supported {
+		tag, err := language.Parse(lang)
+		if err != nil {
+			return nil, fmt.Errorf("invalid language tag %q: %w", lang, err)
+		}
+		tags = append(tags, tag)
+
+		var doc teamNamesDoc
+		if _, err := toml.DecodeFile(filepath.Join(resourcesDir, "i18n", lang+".toml"), &doc); err != nil {
+			return nil, fmt.Errorf("failed to load team names for %v: %w", lang, err)
+		}
+		teamNames[lang] = doc.Teams
+	}
+
+	return &Registry{
+		resourcesDir: resourcesDir,
+		supported:    supported,
+		matcher:      language.NewMatcher(tags),
+		teamNames:    teamNames,
+	}, nil
+}
+
+// Supported returns the configured supported language tags, in priority order.
+func (r *Registry) Supported() []string {
+	return append([]string(nil), r.supported...)
+}
+
+// Resolve picks a supported language from, in order of precedence: explicitLang (typically an
+// explicit `lang` form field), a `lang` query parameter, then the request's Accept-Language
+// header. It always returns one of the configured supported languages.
+func (r *Registry) Resolve(explicitLang string, req *http.Request) string {
+	if r.isSupported(explicitLang) {
+		return explicitLang
+	}
+
+	if q := req.URL.Query().Get("lang"); r.isSupported(q) {
+		return q
+	}
+
+	tags, _, err := language.ParseAcceptLanguage(req.Header.Get("Accept-Language"))
+	if err != nil || len(tags) == 0 {
+		return r.supported[0]
+	}
+
+	_, index, _ := r.matcher.Match(tags...)
+	return r.supported[index]
+}
+
+func (r *Registry) isSupported(lang string) bool {
+	_, ok := r.teamNames[lang]
+	return ok
+}
+
+// HasTeam reports whether teamID is known in the fallback language, i.e. the one returned by
+// Resolve when nothing else matches. Team identifiers are expected to be the same across every
+// supported language's translations, so this doubles as a language-agnostic validity check.
+func (r *Registry) HasTeam(teamID string) bool {
+	_, ok := r.teamNames[r.supported[0]][teamID]
+	return ok
+}
+
+// TeamName returns teamID's localized display name in lang, or an error if lang or teamID is
+// unknown.
+func (r *Registry) TeamName(lang, teamID string) (string, error) {
+	names, ok := r.teamNames[lang]
+	if !ok {
+		return "", fmt.Errorf("unsupported language: %v", lang)
+	}
+
+	name, ok := names[teamID]
+	if !ok {
+		return "", fmt.Errorf("unknown team identifier: %v", teamID)
+	}
+
+	return name, nil
+}
+
+// TemplatePath returns the typst template path for lang.
+func (r *Registry) TemplatePath(lang string) string {
+	return filepath.Join(r.resourcesDir, "pandoc", "templates", lang, "typst.template")
+}
@@ -0,0 +1,150 @@
+package i18n
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// newTestRegistry builds a Registry over a temporary resourcesDir populated with i18n/{lang}.toml
+// files for "en" and "de", each mapping team-esm to a localized display name.
+func newTestRegistry(t *testing.T) *Registry {
+	t.Helper()
+
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "i18n"), 0750); err != nil {
+		t.Fatalf("failed to create i18n dir: %v", err)
+	}
+
+	writeTOML(t, filepath.Join(dir, "i18n", "en.toml"), "[teams]\nteam-esm = \"Team ESM\"\n")
+	writeTOML(t, filepath.Join(dir, "i18n", "de.toml"), "[teams]\nteam-esm = \"Team ESM (DE)\"\n")
+
+	r, err := New(dir, []string{"en", "de"})
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+
+	return r
+}
+
+func writeTOML(t *testing.T, path, contents string) {
+	t.Helper()
+
+	if err := os.WriteFile(path, []byte(contents), 0640); err != nil {
+		t.Fatalf("failed to write %v: %v", path, err)
+	}
+}
+
+func TestNewRejectsNoSupportedLanguages(t *testing.T) {
+	if _, err := New(t.TempDir(), nil); err == nil {
+		t.Fatalf("expected an error for an empty supported language list")
+	}
+}
+
+func TestNewRejectsMissingTranslationFile(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "i18n"), 0750); err != nil {
+		t.Fatalf("failed to create i18n dir: %v", err)
+	}
+	writeTOML(t, filepath.Join(dir, "i18n", "en.toml"), "[teams]\n")
+
+	if _, err := New(dir, []string{"en", "de"}); err == nil {
+		t.Fatalf("expected an error when de.toml doesn't exist")
+	}
+}
+
+func TestResolvePrefersExplicitLang(t *testing.T) {
+	r := newTestRegistry(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/?lang=en", nil)
+	req.Header.Set("Accept-Language", "en")
+
+	if got := r.Resolve("de", req); got != "de" {
+		t.Fatalf("Resolve = %v, want de", got)
+	}
+}
+
+func TestResolveFallsBackToQueryParam(t *testing.T) {
+	r := newTestRegistry(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/?lang=de", nil)
+	req.Header.Set("Accept-Language", "en")
+
+	if got := r.Resolve("", req); got != "de" {
+		t.Fatalf("Resolve = %v, want de", got)
+	}
+}
+
+func TestResolveFallsBackToAcceptLanguage(t *testing.T) {
+	r := newTestRegistry(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Language", "de")
+
+	if got := r.Resolve("", req); got != "de" {
+		t.Fatalf("Resolve = %v, want de", got)
+	}
+}
+
+func TestResolveFallsBackToDefaultLanguage(t *testing.T) {
+	r := newTestRegistry(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+
+	if got := r.Resolve("", req); got != "en" {
+		t.Fatalf("Resolve = %v, want en (the first supported language)", got)
+	}
+}
+
+func TestResolveIgnoresUnsupportedExplicitLang(t *testing.T) {
+	r := newTestRegistry(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+
+	if got := r.Resolve("fr", req); got != "en" {
+		t.Fatalf("Resolve = %v, want en when explicitLang isn't supported", got)
+	}
+}
+
+func TestHasTeam(t *testing.T) {
+	r := newTestRegistry(t)
+
+	if !r.HasTeam("team-esm") {
+		t.Fatalf("expected team-esm to be known")
+	}
+	if r.HasTeam("team-nonexistent") {
+		t.Fatalf("expected team-nonexistent to be unknown")
+	}
+}
+
+func TestTeamName(t *testing.T) {
+	r := newTestRegistry(t)
+
+	name, err := r.TeamName("de", "team-esm")
+	if err != nil {
+		t.Fatalf("TeamName returned error: %v", err)
+	}
+	if name != "Team ESM (DE)" {
+		t.Fatalf("TeamName = %q, want %q", name, "Team ESM (DE)")
+	}
+
+	if _, err := r.TeamName("fr", "team-esm"); err == nil {
+		t.Fatalf("expected an error for an unsupported language")
+	}
+
+	if _, err := r.TeamName("en", "team-nonexistent"); err == nil {
+		t.Fatalf("expected an error for an unknown team identifier")
+	}
+}
+
+func TestTemplatePath(t *testing.T) {
+	r := newTestRegistry(t)
+
+	got := r.TemplatePath("de")
+	want := filepath.Join(r.resourcesDir, "pandoc", "templates", "de", "typst.template")
+	if got != want {
+		t.Fatalf("TemplatePath = %v, want %v", got, want)
+	}
+}
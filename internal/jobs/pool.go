@@ -0,0 +1,487 @@
+// Package jobs runs Markdown-to-PDF conversions on a bounded worker pool so slow pandoc
+// invocations no longer block the HTTP connection they arrived on.
+package jobs
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/LeipzigeSports/les-report-md-to-pdf/internal/cache"
+	"github.com/LeipzigeSports/les-report-md-to-pdf/internal/pandoc"
+	"github.com/LeipzigeSports/les-report-md-to-pdf/internal/store"
+)
+
+// resultRetention bounds how long a finished job's rendered PDF and map entry stick around
+// waiting to be fetched via GET /jobs/{id}/result. RunJanitor reclaims anything older, so a
+// client that never fetches its result doesn't leak a temporary file forever.
+const resultRetention = 10 * time.Minute
+
+// jobIDAlphabet and jobIDLength size a job ID so it can't be enumerated by walking sequential
+// integers: unlike a report's archive ID, a job ID also has to gate access to another team's
+// pandoc stderr and rendered PDF via GET /jobs/{id}/*.
+const jobIDAlphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+const jobIDLength = 8
+
+func generateJobID() (string, error) {
+	raw := make([]byte, jobIDLength)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+
+	id := make([]byte, jobIDLength)
+	for i, b := range raw {
+		id[i] = jobIDAlphabet[int(b)%len(jobIDAlphabet)]
+	}
+
+	return string(id), nil
+}
+
+// Status is a job's position in its lifecycle.
+type Status string
+
+const (
+	StatusQueued  Status = "queued"
+	StatusRunning Status = "running"
+	StatusDone    Status = "done"
+	StatusFailed  Status = "failed"
+	StatusTimeout Status = "timeout"
+)
+
+// ErrQueueFull is returned by Submit when the queue is already at capacity.
+var ErrQueueFull = errors.New("job queue is full")
+
+// Event is a single state transition or pandoc stderr line pushed to a job's subscribers.
+type Event struct {
+	Status Status `json:"status"`
+	Line   string `json:"line,omitempty"`
+}
+
+// Job tracks the lifecycle of a single queued report conversion.
+type Job struct {
+	ID           string
+	TeamID       string
+	TeamName     string
+	Lang         string
+	TemplatePath string
+	archive      bool
+	mdPath       string
+
+	mu          sync.Mutex
+	status      Status
+	resultPath  string
+	reportURL   string
+	err         error
+	completedAt time.Time
+	cacheOwned  bool // true if resultPath is owned by a cache.Cache and must not be deleted here
+	cleaned     bool
+	listeners   map[chan Event]struct{}
+}
+
+func newJob(id, teamID, teamName, lang, templatePath, mdPath string, archive bool) *Job {
+	return &Job{
+		ID:           id,
+		TeamID:       teamID,
+		TeamName:     teamName,
+		Lang:         lang,
+		TemplatePath: templatePath,
+		archive:      archive,
+		mdPath:       mdPath,
+		status:       StatusQueued,
+		listeners:    make(map[chan Event]struct{}),
+	}
+}
+
+// Status returns the job's current state.
+func (j *Job) Status() Status {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.status
+}
+
+// ReportURL returns the archived report's share URL, if the job requested archiving and has
+// completed successfully.
+func (j *Job) ReportURL() string {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.reportURL
+}
+
+// Result returns the converted PDF's path once the job is done, or an error describing why it
+// isn't available: still in flight, failed, or timed out.
+func (j *Job) Result() (string, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	switch j.status {
+	case StatusDone:
+		return j.resultPath, nil
+	case StatusFailed, StatusTimeout:
+		return "", j.err
+	default:
+		return "", fmt.Errorf("job is still %v", j.status)
+	}
+}
+
+func (j *Job) subscribe() chan Event {
+	ch := make(chan Event, 8)
+	j.mu.Lock()
+	j.listeners[ch] = struct{}{}
+	j.mu.Unlock()
+	return ch
+}
+
+func (j *Job) unsubscribe(ch chan Event) {
+	j.mu.Lock()
+	delete(j.listeners, ch)
+	j.mu.Unlock()
+	close(ch)
+}
+
+func (j *Job) emit(evt Event) {
+	j.mu.Lock()
+	listeners := make([]chan Event, 0, len(j.listeners))
+	for ch := range j.listeners {
+		listeners = append(listeners, ch)
+	}
+	j.mu.Unlock()
+
+	for _, ch := range listeners {
+		select {
+		case ch <- evt:
+		default:
+			// slow subscriber; drop the event rather than block the worker
+		}
+	}
+}
+
+func (j *Job) setStatus(status Status) {
+	j.mu.Lock()
+	j.status = status
+	j.mu.Unlock()
+	j.emit(Event{Status: status})
+}
+
+func (j *Job) fail(status Status, err error) {
+	j.mu.Lock()
+	j.status = status
+	j.err = err
+	j.completedAt = time.Now()
+	j.mu.Unlock()
+	j.emit(Event{Status: status})
+}
+
+func (j *Job) succeed(resultPath, reportURL string, cacheOwned bool) {
+	j.mu.Lock()
+	j.status = StatusDone
+	j.resultPath = resultPath
+	j.reportURL = reportURL
+	j.cacheOwned = cacheOwned
+	j.completedAt = time.Now()
+	j.mu.Unlock()
+	j.emit(Event{Status: StatusDone})
+}
+
+// terminalSince reports when the job reached a terminal status, and whether it has reached one
+// at all.
+func (j *Job) terminalSince() (time.Time, bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	switch j.status {
+	case StatusDone, StatusFailed, StatusTimeout:
+		return j.completedAt, true
+	default:
+		return time.Time{}, false
+	}
+}
+
+// Cleanup removes the job's rendered PDF from disk, unless it's owned by a cache.Cache (only the
+// cache's own eviction may remove those). It is safe to call more than once, or before a result
+// exists; only the first call on an un-cached, present result actually deletes anything. Call it
+// once a job's result has been consumed, e.g. after GET /jobs/{id}/result has served it or the
+// job has been archived to the report store.
+func (j *Job) Cleanup() {
+	j.mu.Lock()
+	path := j.resultPath
+	skip := j.cleaned || j.cacheOwned || path == ""
+	j.cleaned = true
+	j.mu.Unlock()
+
+	if skip {
+		return
+	}
+
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		log.Printf("job %v: failed to delete result file: %v\n", j.ID, err)
+	}
+}
+
+// stderrRelay forwards each line pandoc writes to stderr as an Event to the job's subscribers.
+type stderrRelay struct {
+	job *Job
+	buf []byte
+}
+
+func (r *stderrRelay) Write(p []byte) (int, error) {
+	r.buf = append(r.buf, p...)
+
+	for {
+		i := bytes.IndexByte(r.buf, '\n')
+		if i < 0 {
+			break
+		}
+
+		line := string(r.buf[:i])
+		r.buf = r.buf[i+1:]
+		r.job.emit(Event{Status: r.job.Status(), Line: line})
+	}
+
+	return len(p), nil
+}
+
+// Pool runs queued conversion jobs across a fixed number of worker goroutines.
+type Pool struct {
+	converter     *pandoc.Converter
+	store         *store.Store // optional; archives jobs submitted with archive=true
+	cache         *cache.Cache // optional; deduplicates identical conversions
+	pandocVersion string       // included in cache keys; ignored if cache is nil
+	workers       int
+	queue         chan *Job
+	jobs          sync.Map // id -> *Job
+}
+
+// NewPool creates a Pool with the given worker concurrency and queue depth, backed by converter
+// for the pandoc invocations. st may be nil, in which case jobs may not request archiving. cch
+// may be nil, in which case every job invokes pandoc directly; pandocVersion is ignored in that
+// case.
+func NewPool(converter *pandoc.Converter, st *store.Store, cch *cache.Cache, pandocVersion string, workers, queueSize int) *Pool {
+	return &Pool{
+		converter:     converter,
+		store:         st,
+		cache:         cch,
+		pandocVersion: pandocVersion,
+		workers:       workers,
+		queue:         make(chan *Job, queueSize),
+	}
+}
+
+// Start launches the pool's worker goroutines. They run until ctx is done.
+func (p *Pool) Start(ctx context.Context) {
+	for i := 0; i < p.workers; i++ {
+		go p.worker(ctx)
+	}
+}
+
+// RunJanitor periodically sweeps jobs that finished more than resultRetention ago, deleting their
+// rendered PDF (unless it's owned by the cache) and dropping them from the pool, so jobs whose
+// result is never fetched don't leak temporary files or grow the job map forever. It runs until
+// ctx is done.
+func (p *Pool) RunJanitor(ctx context.Context) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	log.Printf("job janitor running every %v\n", time.Minute)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.sweep()
+		}
+	}
+}
+
+func (p *Pool) sweep() {
+	p.jobs.Range(func(key, value interface{}) bool {
+		job := value.(*Job)
+
+		completedAt, terminal := job.terminalSince()
+		if !terminal || time.Since(completedAt) < resultRetention {
+			return true
+		}
+
+		job.Cleanup()
+		p.jobs.Delete(key)
+		return true
+	})
+}
+
+// allocateJobID generates a random job ID, retrying on the unlikely event of a collision with a
+// still-tracked job.
+func (p *Pool) allocateJobID() (string, error) {
+	for attempt := 0; attempt < 5; attempt++ {
+		id, err := generateJobID()
+		if err != nil {
+			return "", fmt.Errorf("failed to generate job id: %w", err)
+		}
+
+		if _, exists := p.jobs.Load(id); !exists {
+			return id, nil
+		}
+	}
+
+	return "", fmt.Errorf("failed to allocate a unique job id")
+}
+
+// Submit writes mdBytes to a temporary input file and enqueues a conversion job for the team
+// identified by teamID/teamName, rendered with templatePath and lang. If archive is true and
+// the pool was built with a store, the resulting PDF is persisted to the archive under teamID
+// once the job succeeds. It returns ErrQueueFull if the queue is already at capacity.
+func (p *Pool) Submit(teamID, teamName, lang, templatePath string, mdBytes []byte, archive bool) (*Job, error) {
+	tmpIn, err := os.CreateTemp("", "pandoc-job-input-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary input file: %w", err)
+	}
+
+	if _, err := tmpIn.Write(mdBytes); err != nil {
+		tmpIn.Close()
+		os.Remove(tmpIn.Name())
+		return nil, fmt.Errorf("failed to write temporary input file: %w", err)
+	}
+	tmpIn.Close()
+
+	id, err := p.allocateJobID()
+	if err != nil {
+		os.Remove(tmpIn.Name())
+		return nil, err
+	}
+
+	job := newJob(id, teamID, teamName, lang, templatePath, tmpIn.Name(), archive)
+
+	select {
+	case p.queue <- job:
+		p.jobs.Store(id, job)
+		return job, nil
+	default:
+		os.Remove(tmpIn.Name())
+		return nil, ErrQueueFull
+	}
+}
+
+// Get looks up a previously submitted job by ID.
+func (p *Pool) Get(id string) (*Job, bool) {
+	v, ok := p.jobs.Load(id)
+	if !ok {
+		return nil, false
+	}
+	return v.(*Job), true
+}
+
+// Subscribe returns a channel of events for job, and a function to stop receiving them. The
+// channel is closed once unsubscribe is called.
+func Subscribe(job *Job) (<-chan Event, func()) {
+	ch := job.subscribe()
+	return ch, func() { job.unsubscribe(ch) }
+}
+
+func (p *Pool) worker(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case job := <-p.queue:
+			p.run(ctx, job)
+		}
+	}
+}
+
+func (p *Pool) run(ctx context.Context, job *Job) {
+	job.setStatus(StatusRunning)
+	defer os.Remove(job.mdPath)
+
+	relay := &stderrRelay{job: job}
+
+	build := func() (string, error) {
+		// Building directly under the cache's own directory (when configured) guarantees
+		// cache.Render's later adoption of this file is a same-filesystem rename.
+		outputDir := ""
+		if p.cache != nil {
+			outputDir = p.cache.TempDir()
+		}
+
+		tmpOut, err := os.CreateTemp(outputDir, "pandoc-job-output-")
+		if err != nil {
+			return "", fmt.Errorf("failed to create temporary output file: %w", err)
+		}
+
+		if err := p.converter.ConvertTo(ctx, job.mdPath, tmpOut.Name(), job.TemplatePath, job.TeamName, job.Lang, relay); err != nil {
+			os.Remove(tmpOut.Name())
+			return "", err
+		}
+
+		return tmpOut.Name(), nil
+	}
+
+	var pdfPath string
+	var err error
+
+	if p.cache != nil {
+		mdBytes, readErr := os.ReadFile(job.mdPath)
+		if readErr != nil {
+			job.fail(StatusFailed, fmt.Errorf("failed to read job source: %w", readErr))
+			return
+		}
+
+		key, keyErr := cache.Key(mdBytes, job.TeamID, job.TemplatePath, p.converter.FontsPath, p.pandocVersion)
+		if keyErr != nil {
+			job.fail(StatusFailed, fmt.Errorf("failed to compute cache key: %w", keyErr))
+			return
+		}
+
+		pdfPath, _, err = p.cache.Render(key, build)
+	} else {
+		pdfPath, err = build()
+	}
+
+	if err != nil {
+		if errors.Is(err, pandoc.ErrTimeout) {
+			job.fail(StatusTimeout, err)
+		} else {
+			job.fail(StatusFailed, err)
+		}
+		return
+	}
+
+	cacheOwned := p.cache != nil
+
+	if job.archive && p.store != nil {
+		if reportURL, err := p.archive(job, pdfPath); err != nil {
+			log.Printf("job %v: failed to archive result: %v\n", job.ID, err)
+			job.succeed(pdfPath, "", cacheOwned)
+		} else {
+			job.succeed(pdfPath, reportURL, cacheOwned)
+			// The PDF's bytes are now in the archive; the job's own copy is no longer needed.
+			job.Cleanup()
+		}
+		return
+	}
+
+	job.succeed(pdfPath, "", cacheOwned)
+}
+
+func (p *Pool) archive(job *Job, pdfPath string) (string, error) {
+	mdBytes, err := os.ReadFile(job.mdPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read job source: %w", err)
+	}
+
+	pdfBytes, err := os.ReadFile(pdfPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read job result: %w", err)
+	}
+
+	entry, err := p.store.Save(job.TeamID, mdBytes, pdfBytes)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("/reports/%v", entry.ID), nil
+}
@@ -0,0 +1,130 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/LeipzigeSports/les-report-md-to-pdf/internal/pandoc"
+)
+
+// sleepyConverter writes a shell script that sleeps for d before exiting 0, and returns a
+// pandoc.Converter configured to run it in place of the real pandoc binary with the given
+// timeout. The script ignores whatever arguments ConvertTo passes it.
+func sleepyConverter(t *testing.T, sleep, timeout time.Duration) *pandoc.Converter {
+	t.Helper()
+
+	script, err := os.CreateTemp("", "fake-pandoc-")
+	if err != nil {
+		t.Fatalf("failed to create fake pandoc script: %v", err)
+	}
+	t.Cleanup(func() { os.Remove(script.Name()) })
+
+	contents := fmt.Sprintf("#!/bin/sh\nsleep %v\n", sleep.Seconds())
+	if _, err := script.WriteString(contents); err != nil {
+		t.Fatalf("failed to write fake pandoc script: %v", err)
+	}
+	script.Close()
+
+	if err := os.Chmod(script.Name(), 0700); err != nil {
+		t.Fatalf("failed to make fake pandoc script executable: %v", err)
+	}
+
+	return pandoc.NewConverter(script.Name(), "", timeout)
+}
+
+func waitForStatus(t *testing.T, job *Job, want Status) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if job.Status() == want {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	t.Fatalf("job never reached status %v, last status %v", want, job.Status())
+}
+
+func TestSubmitAssignsUnguessableID(t *testing.T) {
+	pool := NewPool(sleepyConverter(t, 0, time.Second), nil, nil, "", 0, 1)
+
+	job, err := pool.Submit("team-a", "Team A", "en", "template.typ", []byte("# hello"), false)
+	if err != nil {
+		t.Fatalf("Submit returned error: %v", err)
+	}
+
+	if len(job.ID) != jobIDLength {
+		t.Fatalf("expected job ID of length %v, got %q", jobIDLength, job.ID)
+	}
+
+	for _, r := range job.ID {
+		if !strings.ContainsRune(jobIDAlphabet, r) {
+			t.Fatalf("job ID %q contains a character outside jobIDAlphabet", job.ID)
+		}
+	}
+
+	if strings.HasPrefix(job.ID, "job-") {
+		t.Fatalf("job ID %q looks like the old sequential scheme", job.ID)
+	}
+
+	got, ok := pool.Get(job.ID)
+	if !ok || got != job {
+		t.Fatalf("Get(%q) did not return the submitted job", job.ID)
+	}
+}
+
+func TestSubmitReturnsErrQueueFullWhenSaturated(t *testing.T) {
+	pool := NewPool(sleepyConverter(t, 0, time.Second), nil, nil, "", 0, 1)
+
+	if _, err := pool.Submit("team-a", "Team A", "en", "template.typ", []byte("# one"), false); err != nil {
+		t.Fatalf("first Submit returned error: %v", err)
+	}
+
+	// No workers are running, so the queue's single slot is still occupied by the first job.
+	if _, err := pool.Submit("team-a", "Team A", "en", "template.typ", []byte("# two"), false); err != ErrQueueFull {
+		t.Fatalf("expected ErrQueueFull, got %v", err)
+	}
+}
+
+func TestWorkerRunsQueuedJobToCompletion(t *testing.T) {
+	pool := NewPool(sleepyConverter(t, 0, time.Second), nil, nil, "", 1, 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	pool.Start(ctx)
+
+	job, err := pool.Submit("team-a", "Team A", "en", "template.typ", []byte("# hello"), false)
+	if err != nil {
+		t.Fatalf("Submit returned error: %v", err)
+	}
+
+	waitForStatus(t, job, StatusDone)
+
+	if _, err := job.Result(); err != nil {
+		t.Fatalf("Result returned error for a done job: %v", err)
+	}
+}
+
+func TestWorkerMarksSlowJobAsTimedOut(t *testing.T) {
+	pool := NewPool(sleepyConverter(t, 200*time.Millisecond, 20*time.Millisecond), nil, nil, "", 1, 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	pool.Start(ctx)
+
+	job, err := pool.Submit("team-a", "Team A", "en", "template.typ", []byte("# hello"), false)
+	if err != nil {
+		t.Fatalf("Submit returned error: %v", err)
+	}
+
+	waitForStatus(t, job, StatusTimeout)
+
+	if _, err := job.Result(); err == nil {
+		t.Fatalf("expected Result to return an error for a timed-out job")
+	}
+}
@@ -0,0 +1,74 @@
+// Package pandoc wraps invocation of the pandoc CLI for converting Markdown reports into PDFs.
+package pandoc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// ErrTimeout is returned by Convert/ConvertTo when Timeout elapses before pandoc exits.
+var ErrTimeout = errors.New("pandoc conversion timed out")
+
+// Converter runs pandoc conversions against a fixed executable and font directory. The typst
+// template and language vary per conversion, since a single deployment may serve several
+// languages, each with its own template.
+type Converter struct {
+	Executable string
+	FontsPath  string
+	Timeout    time.Duration
+}
+
+// NewConverter builds a Converter from the given executable, font directory, and per-conversion
+// timeout.
+func NewConverter(executable, fontsPath string, timeout time.Duration) *Converter {
+	return &Converter{
+		Executable: executable,
+		FontsPath:  fontsPath,
+		Timeout:    timeout,
+	}
+}
+
+// Version runs executable --version and returns its first line, e.g. "pandoc 3.1.11". Useful as
+// a fingerprint for cache keys, since output can change across pandoc releases even when the
+// input, team, and template don't.
+func Version(ctx context.Context, executable string) (string, error) {
+	out, err := exec.CommandContext(ctx, executable, "--version").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to query pandoc version: %w", err)
+	}
+
+	line, _, _ := strings.Cut(string(out), "\n")
+	return strings.TrimSpace(line), nil
+}
+
+// Convert renders the Markdown file at inputPath into a PDF at outputPath using templatePath,
+// passing team and lang as the `team` and `lang` typst variables. It returns once pandoc exits,
+// ctx is cancelled, or Timeout elapses.
+func (c *Converter) Convert(ctx context.Context, inputPath, outputPath, templatePath, team, lang string) error {
+	return c.ConvertTo(ctx, inputPath, outputPath, templatePath, team, lang, io.Discard)
+}
+
+// ConvertTo behaves like Convert, additionally streaming pandoc's stderr output to stderr as it
+// is produced.
+func (c *Converter) ConvertTo(ctx context.Context, inputPath, outputPath, templatePath, team, lang string, stderr io.Writer) error {
+	cmdCtx, cancel := context.WithTimeout(ctx, c.Timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(cmdCtx, c.Executable, inputPath, "-f", "markdown", "-o", outputPath, "-t", "pdf", "--template", templatePath, "-V", fmt.Sprintf("team=%v", team), "-V", fmt.Sprintf("lang=%v", lang), "--pdf-engine", "typst", "--pdf-engine-opt", "--pdf-standard=a-2b")
+	cmd.Env = append(cmd.Environ(), fmt.Sprintf("TYPST_FONT_PATHS=%v", c.FontsPath))
+	cmd.Stderr = stderr
+
+	if err := cmd.Run(); err != nil {
+		if errors.Is(cmdCtx.Err(), context.DeadlineExceeded) {
+			return ErrTimeout
+		}
+		return fmt.Errorf("failed to execute pandoc: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,297 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/LeipzigeSports/les-report-md-to-pdf/internal/pandoc"
+)
+
+// debounceWindow coalesces bursts of filesystem events (e.g. editor atomic saves) into a
+// single rebuild.
+const debounceWindow = 150 * time.Millisecond
+
+// DevOptions configures the live-reload preview server started by RunDev.
+type DevOptions struct {
+	MdPath       string
+	WatchDirs    []string
+	Team         string
+	Lang         string
+	TemplatePath string
+	Host         string
+	Port         int
+	Converter    *pandoc.Converter
+}
+
+// devState holds the most recently built PDF and fans out reload notifications to connected
+// SSE clients.
+type devState struct {
+	mu        sync.Mutex
+	pdfPath   string
+	buildErr  error
+	listeners map[chan struct{}]struct{}
+}
+
+func newDevState() *devState {
+	return &devState{listeners: make(map[chan struct{}]struct{})}
+}
+
+func (s *devState) subscribe() chan struct{} {
+	ch := make(chan struct{}, 1)
+	s.mu.Lock()
+	s.listeners[ch] = struct{}{}
+	s.mu.Unlock()
+	return ch
+}
+
+func (s *devState) unsubscribe(ch chan struct{}) {
+	s.mu.Lock()
+	delete(s.listeners, ch)
+	s.mu.Unlock()
+	close(ch)
+}
+
+func (s *devState) setResult(pdfPath string, err error) {
+	s.mu.Lock()
+	s.pdfPath = pdfPath
+	s.buildErr = err
+	listeners := make([]chan struct{}, 0, len(s.listeners))
+	for ch := range s.listeners {
+		listeners = append(listeners, ch)
+	}
+	s.mu.Unlock()
+
+	// Only a successful rebuild triggers a reload; after a failed one the page keeps its last PDF.
+	if err != nil {
+		return
+	}
+
+	for _, ch := range listeners {
+		select {
+		case ch <- struct{}{}:
+		default:
+			// listener already has a pending reload queued
+		}
+	}
+}
+
+func (s *devState) result() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.pdfPath, s.buildErr
+}
+
+// RunDev watches opts.MdPath and opts.WatchDirs, rebuilding the PDF through opts.Converter on
+// every change, and serves a preview page at "/" with an embedded PDF iframe plus an SSE
+// channel at "/events" that pushes a reload event after each successful rebuild.
+func RunDev(ctx context.Context, opts DevOptions) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create file watcher: %w", err)
+	}
+	defer watcher.Close()
+
+	// Watch opts.MdPath's containing directory rather than the file itself: fsnotify drops a
+	// watch on the file the moment it's replaced, which is exactly what editors like vim do
+	// for an "atomic" save (write a temp file, then rename it over the original) -- the first
+	// save would fire, but every save after that would go completely unnoticed.
+	mdDir := filepath.Dir(opts.MdPath)
+	if err := watcher.Add(mdDir); err != nil {
+		return fmt.Errorf("failed to watch %v: %w", mdDir, err)
+	}
+
+	for _, dir := range opts.WatchDirs {
+		if err := watcher.Add(dir); err != nil {
+			return fmt.Errorf("failed to watch %v: %w", dir, err)
+		}
+	}
+
+	state := newDevState()
+
+	rebuild := func() {
+		tmpOut, err := os.CreateTemp("", "pandoc-dev-")
+		if err != nil {
+			log.Printf("failed to create temporary output file: %v\n", err)
+			state.setResult("", err)
+			return
+		}
+
+		if err := opts.Converter.Convert(ctx, opts.MdPath, tmpOut.Name(), opts.TemplatePath, opts.Team, opts.Lang); err != nil {
+			log.Printf("dev rebuild failed: %v\n", err)
+			state.setResult("", err)
+			return
+		}
+
+		prevPath, _ := state.result()
+		state.setResult(tmpOut.Name(), nil)
+
+		if prevPath != "" {
+			if err := os.Remove(prevPath); err != nil {
+				log.Printf("failed to delete stale preview file: %v\n", err)
+			}
+		}
+
+		log.Println("rebuilt preview PDF")
+	}
+
+	go watchLoop(ctx, watcher, debounceWindow, opts.MdPath, rebuild)
+
+	log.Println("building initial preview")
+	rebuild()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", devIndexHandler)
+	mux.HandleFunc("/preview.pdf", devPdfHandler(state))
+	mux.HandleFunc("/events", devEventsHandler(state))
+
+	srv := &http.Server{
+		Addr:    fmt.Sprintf("%v:%v", opts.Host, opts.Port),
+		Handler: mux,
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+
+	log.Printf("running dev preview server on %v\n", srv.Addr)
+
+	if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		return fmt.Errorf("dev server failed: %w", err)
+	}
+
+	return nil
+}
+
+// watchLoop drains fsnotify events until the debounce window has passed with no new events,
+// then runs rebuild. It exits once ctx is done. Since the watcher is registered on mdPath's
+// containing directory rather than mdPath itself (see RunDev), events for other files in that
+// same directory are filtered out; events from opts.WatchDirs, which are watched as whole
+// directories on purpose, are left unfiltered.
+func watchLoop(ctx context.Context, watcher *fsnotify.Watcher, debounce time.Duration, mdPath string, rebuild func()) {
+	var timer *time.Timer
+	mdDir := filepath.Dir(mdPath)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+
+			if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) == 0 {
+				continue
+			}
+
+			if filepath.Dir(event.Name) == mdDir && filepath.Clean(event.Name) != filepath.Clean(mdPath) {
+				continue
+			}
+
+			if timer == nil {
+				timer = time.NewTimer(debounce)
+			} else {
+				if !timer.Stop() {
+					<-timer.C
+				}
+				timer.Reset(debounce)
+			}
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			log.Printf("file watcher error: %v\n", err)
+
+		case <-timerC(timer):
+			timer = nil
+			rebuild()
+		}
+	}
+}
+
+// timerC returns t's channel, or nil if t hasn't been started yet. Selecting on a nil channel
+// blocks forever, which is exactly what's needed while no debounce is pending.
+func timerC(t *time.Timer) <-chan time.Time {
+	if t == nil {
+		return nil
+	}
+	return t.C
+}
+
+func devIndexHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprint(w, `<!DOCTYPE html>
+<html>
+<head><title>reportconv dev preview</title></head>
+<body style="margin:0">
+<iframe id="preview" src="/preview.pdf" style="border:none;width:100vw;height:100vh"></iframe>
+<script>
+const events = new EventSource("/events");
+events.addEventListener("reload", () => {
+  document.getElementById("preview").src = "/preview.pdf?t=" + Date.now();
+});
+</script>
+</body>
+</html>`)
+}
+
+func devPdfHandler(state *devState) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		pdfPath, err := state.result()
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprintf(w, "last build failed: %v", err)
+			return
+		}
+
+		if pdfPath == "" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		http.ServeFile(w, r, pdfPath)
+	}
+}
+
+func devEventsHandler(state *devState) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		ch := state.subscribe()
+		defer state.unsubscribe(ch)
+
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+
+			case <-ch:
+				fmt.Fprint(w, "event: reload\ndata: {}\n\n")
+				flusher.Flush()
+			}
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package server
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+const testDebounce = 30 * time.Millisecond
+
+func newWatchedDir(t *testing.T) (dir string, watcher *fsnotify.Watcher) {
+	t.Helper()
+
+	dir = t.TempDir()
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		t.Fatalf("failed to create watcher: %v", err)
+	}
+	t.Cleanup(func() { watcher.Close() })
+
+	if err := watcher.Add(dir); err != nil {
+		t.Fatalf("failed to watch %v: %v", dir, err)
+	}
+
+	return dir, watcher
+}
+
+func waitForRebuilds(t *testing.T, count *atomic.Int32, want int32) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if count.Load() >= want {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	t.Fatalf("rebuild was called %v times, want at least %v", count.Load(), want)
+}
+
+func TestWatchLoopDebouncesBurstOfWrites(t *testing.T) {
+	dir, watcher := newWatchedDir(t)
+	mdPath := filepath.Join(dir, "report.md")
+
+	var rebuilds atomic.Int32
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go watchLoop(ctx, watcher, testDebounce, mdPath, func() { rebuilds.Add(1) })
+
+	// A burst of writes within the debounce window should coalesce into a single rebuild,
+	// mirroring an editor's atomic-save sequence.
+	for i := 0; i < 5; i++ {
+		if err := os.WriteFile(mdPath, []byte("content"), 0640); err != nil {
+			t.Fatalf("failed to write %v: %v", mdPath, err)
+		}
+		time.Sleep(testDebounce / 3)
+	}
+
+	waitForRebuilds(t, &rebuilds, 1)
+	time.Sleep(testDebounce * 2)
+
+	if got := rebuilds.Load(); got != 1 {
+		t.Fatalf("rebuild was called %v times for a single debounced burst, want 1", got)
+	}
+}
+
+func TestWatchLoopIgnoresOtherFilesInTheSameDirectory(t *testing.T) {
+	dir, watcher := newWatchedDir(t)
+	mdPath := filepath.Join(dir, "report.md")
+	otherPath := filepath.Join(dir, "unrelated.txt")
+
+	var rebuilds atomic.Int32
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go watchLoop(ctx, watcher, testDebounce, mdPath, func() { rebuilds.Add(1) })
+
+	if err := os.WriteFile(otherPath, []byte("content"), 0640); err != nil {
+		t.Fatalf("failed to write %v: %v", otherPath, err)
+	}
+
+	time.Sleep(testDebounce * 3)
+
+	if got := rebuilds.Load(); got != 0 {
+		t.Fatalf("expected writes to an unrelated file in the watched directory to be ignored, got %v rebuilds", got)
+	}
+}
+
+func TestWatchLoopStopsOnContextDone(t *testing.T) {
+	_, watcher := newWatchedDir(t)
+	mdPath := filepath.Join(t.TempDir(), "report.md")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	go func() {
+		watchLoop(ctx, watcher, testDebounce, mdPath, func() {})
+		close(done)
+	}()
+
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("watchLoop did not return after its context was cancelled")
+	}
+}
+
+func TestDevStateNotifiesSubscribersOnSetResult(t *testing.T) {
+	state := newDevState()
+	ch := state.subscribe()
+	defer state.unsubscribe(ch)
+
+	state.setResult("/tmp/preview.pdf", nil)
+
+	select {
+	case <-ch:
+	case <-time.After(time.Second):
+		t.Fatalf("subscriber was not notified of a result update")
+	}
+
+	path, err := state.result()
+	if err != nil {
+		t.Fatalf("result returned error: %v", err)
+	}
+	if path != "/tmp/preview.pdf" {
+		t.Fatalf("result path = %v, want /tmp/preview.pdf", path)
+	}
+}
+
+func TestDevStateUnsubscribeClosesChannel(t *testing.T) {
+	state := newDevState()
+	ch := state.subscribe()
+
+	state.unsubscribe(ch)
+
+	if _, ok := <-ch; ok {
+		t.Fatalf("expected channel to be closed after unsubscribe")
+	}
+}
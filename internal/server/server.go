@@ -0,0 +1,774 @@
+// Package server implements the HTTP handlers for converting uploaded Markdown reports to PDF.
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/LeipzigeSports/les-report-md-to-pdf/internal/cache"
+	"github.com/LeipzigeSports/les-report-md-to-pdf/internal/config"
+	"github.com/LeipzigeSports/les-report-md-to-pdf/internal/i18n"
+	"github.com/LeipzigeSports/les-report-md-to-pdf/internal/jobs"
+	"github.com/LeipzigeSports/les-report-md-to-pdf/internal/middleware"
+	"github.com/LeipzigeSports/les-report-md-to-pdf/internal/pandoc"
+	"github.com/LeipzigeSports/les-report-md-to-pdf/internal/store"
+)
+
+// queueFullRetryAfter is advertised to clients via Retry-After when the job queue is full.
+const queueFullRetryAfter = "5"
+
+var teamIdLookup = map[string]string{
+	"team-esm":  "E-Sport-Management",
+	"team-hs":   "Hochschulen",
+	"team-oea":  "Ã–ffentlichkeitsarbeit",
+	"team-tech": "Technik",
+	"team-vs":   "Veranstaltungen",
+	"team-vh":   "Vereinsheim",
+}
+
+func tryDeleteFile(f *os.File) {
+	tryDeletePath(f.Name())
+}
+
+func tryDeletePath(path string) {
+	if err := os.Remove(path); err != nil {
+		log.Printf("failed to delete temporary file: %v\n", err)
+	}
+}
+
+// Server serves the report conversion endpoints. Construct it with New.
+type Server struct {
+	cfg             config.Config
+	store           *store.Store   // nil disables the /reports archive endpoints
+	jobs            *jobs.Pool     // nil makes POST / respond synchronously instead of enqueuing
+	i18n            *i18n.Registry // nil serves only cfg's default template and the static teamIdLookup names
+	cache           *cache.Cache   // nil makes every conversion invoke pandoc directly
+	pandocVersion   string         // included in cache keys; ignored if cache is nil
+	securityHeaders middleware.SecurityHeaders
+	auth            func(http.Handler) http.Handler // nil disables JWT-gating of write routes
+}
+
+// New builds a Server for cfg. st may be nil, in which case the archive endpoints are disabled.
+// pool may be nil, in which case POST / runs the conversion synchronously instead of enqueuing
+// a job. reg may be nil, in which case every conversion uses cfg.PandocTypstTemplatePath and team
+// names are resolved from the built-in teamIdLookup. cch may be nil, in which case every
+// conversion invokes pandoc directly and pandocVersion is ignored. If requireAuth is true, POST /
+// and every write route are gated behind an HS256 JWT bearer token signed with jwtSecret, whose
+// team claim must name a team the server knows about.
+func New(cfg config.Config, st *store.Store, pool *jobs.Pool, reg *i18n.Registry, cch *cache.Cache, pandocVersion string, secHeaders middleware.SecurityHeaders, requireAuth bool, jwtSecret string) *Server {
+	s := &Server{cfg: cfg, store: st, jobs: pool, i18n: reg, cache: cch, pandocVersion: pandocVersion, securityHeaders: secHeaders}
+
+	if requireAuth {
+		s.auth = middleware.JWTAuth(jwtSecret, s.validTeam)
+	}
+
+	return s
+}
+
+// validTeam reports whether teamID is a team the server knows about, consulting the language
+// registry when configured and falling back to the static teamIdLookup otherwise.
+func (s *Server) validTeam(teamID string) bool {
+	if s.i18n != nil {
+		return s.i18n.HasTeam(teamID)
+	}
+
+	_, ok := teamIdLookup[teamID]
+	return ok
+}
+
+// gateWrites wraps handler so that state-changing requests (POST, DELETE) must pass s.auth
+// first; GET and other read-only requests are left unauthenticated.
+func (s *Server) gateWrites(handler http.HandlerFunc) http.HandlerFunc {
+	gated := s.auth(handler)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost, http.MethodDelete:
+			gated.ServeHTTP(w, r)
+		default:
+			handler(w, r)
+		}
+	}
+}
+
+// Mux builds the HTTP mux serving this Server's endpoints.
+func (s *Server) Mux() *http.ServeMux {
+	mux := http.NewServeMux()
+
+	index := s.handleIndex
+	reportsItem := s.handleReportsItem
+	if s.auth != nil {
+		index = s.gateWrites(index)
+		reportsItem = s.gateWrites(reportsItem)
+	}
+	mux.HandleFunc("/", index)
+
+	if s.store != nil {
+		reportsCollection := s.handleReportsCollection
+		if s.auth != nil {
+			reportsCollection = s.auth(http.HandlerFunc(reportsCollection)).ServeHTTP
+		}
+		mux.HandleFunc("/reports", reportsCollection)
+		mux.HandleFunc("/reports/", reportsItem)
+	}
+
+	if s.jobs != nil {
+		jobsItem := s.handleJobsItem
+		if s.auth != nil {
+			jobsItem = s.auth(http.HandlerFunc(jobsItem)).ServeHTTP
+		}
+		mux.HandleFunc("/jobs/", jobsItem)
+	}
+
+	if s.i18n != nil {
+		mux.HandleFunc("/languages", s.handleLanguages)
+	}
+
+	if s.cache != nil {
+		cacheHandler := s.handleCache
+		if s.auth != nil {
+			cacheHandler = s.auth(http.HandlerFunc(cacheHandler)).ServeHTTP
+		}
+		mux.HandleFunc("/cache", cacheHandler)
+	}
+
+	return mux
+}
+
+// upload is a parsed, not-yet-converted submission to POST / or POST /reports.
+type upload struct {
+	teamID       string
+	teamName     string
+	lang         string
+	templatePath string
+	mdBytes      []byte
+	archive      bool
+}
+
+// resolveTeam validates teamId and, if a language registry is configured, resolves lang and the
+// localized team name; otherwise it falls back to the static teamIdLookup and cfg's single
+// template path.
+func (s *Server) resolveTeam(teamId, explicitLang string, r *http.Request) (teamName, lang, templatePath string, err error) {
+	if s.i18n == nil {
+		teamName, ok := teamIdLookup[teamId]
+		if !ok {
+			return "", "", "", fmt.Errorf("invalid team identifier: %v", teamId)
+		}
+		return teamName, "", s.cfg.PandocTypstTemplatePath, nil
+	}
+
+	lang = s.i18n.Resolve(explicitLang, r)
+
+	teamName, err = s.i18n.TeamName(lang, teamId)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	return teamName, lang, s.i18n.TemplatePath(lang), nil
+}
+
+// parseUpload validates and reads a multipart conversion request without invoking pandoc. If the
+// request was authenticated by JWTAuth, the submitted team field must match the token's own team
+// claim, so a token can only ever be used to submit on behalf of the team it was issued to.
+func (s *Server) parseUpload(r *http.Request) (*upload, int, error) {
+	if err := r.ParseMultipartForm(32 << 20); err != nil { // 32 MB
+		return nil, http.StatusInternalServerError, fmt.Errorf("failed to parse multipart request body: %w", err)
+	}
+
+	teamIdSlice, ok := r.MultipartForm.Value["team"]
+	if !ok || len(teamIdSlice) == 0 {
+		return nil, http.StatusBadRequest, fmt.Errorf("missing team field")
+	}
+
+	teamId := teamIdSlice[0]
+
+	if authTeam, ok := middleware.TeamFromContext(r.Context()); ok && authTeam != teamId {
+		return nil, http.StatusForbidden, fmt.Errorf("token is not authorized for team %v", teamId)
+	}
+
+	var explicitLang string
+	if langSlice := r.MultipartForm.Value["lang"]; len(langSlice) > 0 {
+		explicitLang = langSlice[0]
+	}
+
+	teamName, lang, templatePath, err := s.resolveTeam(teamId, explicitLang, r)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	archive := formChecked(r.MultipartForm.Value["save"])
+
+	mdFile, _, err := r.FormFile("md-file")
+	// check if an error occurred while reading the md-file form field
+	if err != nil {
+		// if it's any error other than "missing file", abort
+		if !errors.Is(err, http.ErrMissingFile) {
+			return nil, http.StatusInternalServerError, fmt.Errorf("failed to read file: %w", err)
+		}
+
+		// at this point it's clear that md-file wasn't provided, so try md-content next
+		mdContentSlice, ok := r.MultipartForm.Value["md-content"]
+		if !ok || len(mdContentSlice) == 0 {
+			return nil, http.StatusBadRequest, fmt.Errorf("neither md-file nor md-content provided")
+		}
+
+		return &upload{teamID: teamId, teamName: teamName, lang: lang, templatePath: templatePath, mdBytes: []byte(mdContentSlice[0]), archive: archive}, http.StatusOK, nil
+	}
+	defer mdFile.Close()
+
+	mdBytes, err := io.ReadAll(mdFile)
+	if err != nil {
+		return nil, http.StatusInternalServerError, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	return &upload{teamID: teamId, teamName: teamName, lang: lang, templatePath: templatePath, mdBytes: mdBytes, archive: archive}, http.StatusOK, nil
+}
+
+// conversionResult is the outcome of a successful synchronous convert call. cleanup must be
+// invoked once the caller is done with pdfPath.
+type conversionResult struct {
+	teamID   string
+	teamName string
+	mdBytes  []byte
+	pdfPath  string
+	cacheKey string // non-empty when a cache is configured; doubles as the response ETag
+	cacheHit bool
+	cleanup  func()
+}
+
+// setCacheHeaders advertises whether result came from the cache, and its content-addressed ETag,
+// on responses that serve result.pdfPath directly. It is a no-op when no cache is configured.
+func (s *Server) setCacheHeaders(w http.ResponseWriter, result *conversionResult) {
+	if result.cacheKey == "" {
+		return
+	}
+
+	if result.cacheHit {
+		w.Header().Set("X-Cache", "HIT")
+	} else {
+		w.Header().Set("X-Cache", "MISS")
+	}
+	w.Header().Set("ETag", fmt.Sprintf("%q", result.cacheKey))
+}
+
+// convert parses a multipart conversion request and runs it through pandoc synchronously (or
+// serves a cached PDF for identical input), returning the rendered PDF's path along with the
+// submitted Markdown source. The caller must invoke the returned cleanup once done with the
+// result.
+func (s *Server) convert(r *http.Request) (*conversionResult, int, error) {
+	up, status, err := s.parseUpload(r)
+	if err != nil {
+		return nil, status, err
+	}
+
+	tmpIn, err := os.CreateTemp("", "pandoc-input-")
+	if err != nil {
+		return nil, http.StatusInternalServerError, fmt.Errorf("failed to create temporary file: %w", err)
+	}
+
+	log.Printf("created temporary input file at %v\n", tmpIn.Name())
+
+	if _, err := tmpIn.Write(up.mdBytes); err != nil {
+		tmpIn.Close()
+		tryDeleteFile(tmpIn)
+		return nil, http.StatusInternalServerError, fmt.Errorf("failed to write contents to temporary file: %w", err)
+	}
+	tmpIn.Close()
+
+	conv := pandoc.NewConverter(s.cfg.PandocExecutable, s.cfg.PandocFontsPath, s.cfg.PandocTimeout)
+
+	build := func() (string, error) {
+		// Building directly under the cache's own directory (when configured) guarantees
+		// cache.Render's later adoption of this file is a same-filesystem rename.
+		outputDir := ""
+		if s.cache != nil {
+			outputDir = s.cache.TempDir()
+		}
+
+		tmpOut, err := os.CreateTemp(outputDir, "pandoc-output-")
+		if err != nil {
+			return "", fmt.Errorf("failed to create temporary file: %w", err)
+		}
+
+		log.Printf("created temporary output file at %v\n", tmpOut.Name())
+
+		if err := conv.Convert(r.Context(), tmpIn.Name(), tmpOut.Name(), up.templatePath, up.teamName, up.lang); err != nil {
+			tryDeleteFile(tmpOut)
+			return "", fmt.Errorf("failed to execute: %w", err)
+		}
+
+		return tmpOut.Name(), nil
+	}
+
+	var pdfPath, cacheKey string
+	cacheHit := false
+
+	if s.cache != nil {
+		cacheKey, err = cache.Key(up.mdBytes, up.teamID, up.templatePath, s.cfg.PandocFontsPath, s.pandocVersion)
+		if err != nil {
+			tryDeleteFile(tmpIn)
+			return nil, http.StatusInternalServerError, fmt.Errorf("failed to compute cache key: %w", err)
+		}
+
+		pdfPath, cacheHit, err = s.cache.Render(cacheKey, build)
+	} else {
+		pdfPath, err = build()
+	}
+
+	if err != nil {
+		tryDeleteFile(tmpIn)
+		return nil, http.StatusInternalServerError, err
+	}
+
+	// Once a cache is configured, pdfPath is owned by the cache (built or already present) and
+	// must not be deleted here; only the cache's own eviction removes it.
+	cleanupPDF := s.cache == nil
+
+	return &conversionResult{
+		teamID:   up.teamID,
+		teamName: up.teamName,
+		mdBytes:  up.mdBytes,
+		pdfPath:  pdfPath,
+		cacheKey: cacheKey,
+		cacheHit: cacheHit,
+		cleanup: func() {
+			tryDeleteFile(tmpIn)
+			if cleanupPDF {
+				tryDeletePath(pdfPath)
+			}
+		},
+	}, http.StatusOK, nil
+}
+
+func formChecked(values []string) bool {
+	return len(values) > 0 && (values[0] == "on" || values[0] == "true" || values[0] == "1")
+}
+
+func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
+	if r.Method == "GET" {
+		http.ServeFile(w, r, s.cfg.IndexPath)
+		return
+	}
+
+	if r.Method != "POST" {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	if s.jobs == nil {
+		s.handleIndexSync(w, r)
+		return
+	}
+
+	up, status, err := s.parseUpload(r)
+	if err != nil {
+		w.WriteHeader(status)
+		if status == http.StatusInternalServerError {
+			log.Printf("conversion failed: %v\n", err)
+		}
+		return
+	}
+
+	job, err := s.jobs.Submit(up.teamID, up.teamName, up.lang, up.templatePath, up.mdBytes, up.archive && s.store != nil)
+	if err != nil {
+		if errors.Is(err, jobs.ErrQueueFull) {
+			w.Header().Set("Retry-After", queueFullRetryAfter)
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("failed to submit job: %v\n", err)
+		return
+	}
+
+	jobURL := fmt.Sprintf("/jobs/%v", job.ID)
+
+	w.Header().Set("Location", jobURL)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+
+	if err := json.NewEncoder(w).Encode(map[string]string{"jobID": job.ID}); err != nil {
+		log.Printf("failed to write response: %v\n", err)
+	}
+}
+
+// handleIndexSync is the fallback POST / behavior used when no job pool is configured: it
+// converts the upload inline and either streams the PDF or, if archiving was requested, returns
+// the share URL.
+func (s *Server) handleIndexSync(w http.ResponseWriter, r *http.Request) {
+	result, status, err := s.convert(r)
+	if err != nil {
+		w.WriteHeader(status)
+		if status == http.StatusInternalServerError {
+			log.Printf("conversion failed: %v\n", err)
+		}
+		return
+	}
+	defer result.cleanup()
+
+	if s.store != nil && formChecked(r.MultipartForm.Value["save"]) {
+		s.respondWithArchivedReport(w, result)
+		return
+	}
+
+	s.setCacheHeaders(w, result)
+	http.ServeFile(w, r, result.pdfPath)
+}
+
+func (s *Server) handleReportsCollection(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	result, status, err := s.convert(r)
+	if err != nil {
+		w.WriteHeader(status)
+		if status == http.StatusInternalServerError {
+			log.Printf("conversion failed: %v\n", err)
+		}
+		return
+	}
+	defer result.cleanup()
+
+	s.respondWithArchivedReport(w, result)
+}
+
+func (s *Server) respondWithArchivedReport(w http.ResponseWriter, result *conversionResult) {
+	pdfBytes, err := os.ReadFile(result.pdfPath)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("failed to read converted pdf: %v\n", err)
+		return
+	}
+
+	entry, err := s.store.Save(result.teamID, result.mdBytes, pdfBytes)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("failed to archive report: %v\n", err)
+		return
+	}
+
+	reportURL := fmt.Sprintf("/reports/%v", entry.ID)
+
+	w.Header().Set("Location", reportURL)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+
+	if err := json.NewEncoder(w).Encode(map[string]string{"id": entry.ID, "url": reportURL}); err != nil {
+		log.Printf("failed to write response: %v\n", err)
+	}
+}
+
+func (s *Server) handleReportsItem(w http.ResponseWriter, r *http.Request) {
+	rest := strings.TrimPrefix(r.URL.Path, "/reports/")
+	id, sub, _ := strings.Cut(rest, "/")
+
+	if id == "" {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	switch {
+	case sub == "" && r.Method == "GET":
+		s.serveArchivedPDF(w, r, id)
+	case sub == "source" && r.Method == "GET":
+		s.serveArchivedSource(w, r, id)
+	case sub == "" && r.Method == "DELETE":
+		s.deleteArchivedReport(w, r, id)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) serveArchivedPDF(w http.ResponseWriter, r *http.Request, id string) {
+	pdfPath, err := s.store.PDFPath(id)
+	if err != nil {
+		s.respondStoreErr(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`inline; filename="%v.pdf"`, id))
+	http.ServeFile(w, r, pdfPath)
+}
+
+func (s *Server) serveArchivedSource(w http.ResponseWriter, r *http.Request, id string) {
+	srcPath, err := s.store.SourcePath(id)
+	if err != nil {
+		s.respondStoreErr(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+	http.ServeFile(w, r, srcPath)
+}
+
+// deleteArchivedReport deletes the archived report id, after confirming it exists and, if the
+// request was authenticated by JWTAuth, that the token's team owns it; a token can't be used to
+// delete another team's report just by knowing its ID.
+func (s *Server) deleteArchivedReport(w http.ResponseWriter, r *http.Request, id string) {
+	entry, err := s.store.Load(id)
+	if err != nil {
+		s.respondStoreErr(w, err)
+		return
+	}
+
+	if authTeam, ok := middleware.TeamFromContext(r.Context()); ok && authTeam != entry.Team {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	if err := s.store.Delete(id); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("failed to delete archived report %v: %v\n", id, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) respondStoreErr(w http.ResponseWriter, err error) {
+	if errors.Is(err, store.ErrNotFound) {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusInternalServerError)
+	log.Printf("archive lookup failed: %v\n", err)
+}
+
+func (s *Server) handleJobsItem(w http.ResponseWriter, r *http.Request) {
+	rest := strings.TrimPrefix(r.URL.Path, "/jobs/")
+	id, sub, _ := strings.Cut(rest, "/")
+
+	job, ok := s.jobs.Get(id)
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if authTeam, ok := middleware.TeamFromContext(r.Context()); ok && authTeam != job.TeamID {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	switch {
+	case sub == "" && r.Method == "GET":
+		s.handleJobStatus(w, job)
+	case sub == "events" && r.Method == "GET":
+		s.handleJobEvents(w, r, job)
+	case sub == "result" && r.Method == "GET":
+		s.handleJobResult(w, r, job)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) handleJobStatus(w http.ResponseWriter, job *jobs.Job) {
+	body := map[string]string{"status": string(job.Status())}
+	if reportURL := job.ReportURL(); reportURL != "" {
+		body["reportURL"] = reportURL
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		log.Printf("failed to write response: %v\n", err)
+	}
+}
+
+func (s *Server) handleJobEvents(w http.ResponseWriter, r *http.Request, job *jobs.Job) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	// This stream stays open for as long as the job takes to finish, which routinely exceeds
+	// the server's blanket WriteTimeout; clear the per-connection write deadline so a
+	// still-running job's stream isn't killed out from under it.
+	if err := http.NewResponseController(w).SetWriteDeadline(time.Time{}); err != nil {
+		log.Printf("failed to clear write deadline for job event stream: %v\n", err)
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	events, unsubscribe := jobs.Subscribe(job)
+	defer unsubscribe()
+
+	// Subscribing only surfaces *future* transitions. A job can easily already be done by the
+	// time a client opens this connection (it has to submit, read the jobID, then open a
+	// second connection), so send its current status first -- otherwise that client gets no
+	// event at all and hangs until its own timeout.
+	current := jobs.Event{Status: job.Status()}
+	if !writeJobEvent(w, flusher, current) {
+		return
+	}
+
+	switch current.Status {
+	case jobs.StatusDone, jobs.StatusFailed, jobs.StatusTimeout:
+		return
+	}
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+
+		case evt, ok := <-events:
+			if !ok {
+				return
+			}
+
+			if !writeJobEvent(w, flusher, evt) {
+				return
+			}
+
+			switch evt.Status {
+			case jobs.StatusDone, jobs.StatusFailed, jobs.StatusTimeout:
+				return
+			}
+		}
+	}
+}
+
+// writeJobEvent writes evt to w as an SSE event and flushes it, reporting whether the write
+// succeeded.
+func writeJobEvent(w http.ResponseWriter, flusher http.Flusher, evt jobs.Event) bool {
+	payload, err := json.Marshal(evt)
+	if err != nil {
+		log.Printf("failed to marshal job event: %v\n", err)
+		return false
+	}
+
+	if _, err := fmt.Fprintf(w, "event: %v\ndata: %s\n\n", evt.Status, payload); err != nil {
+		log.Printf("failed to write job event: %v\n", err)
+		return false
+	}
+	flusher.Flush()
+
+	return true
+}
+
+func (s *Server) handleJobResult(w http.ResponseWriter, r *http.Request, job *jobs.Job) {
+	// A slow client downloading a large PDF can take longer than the server's blanket
+	// WriteTimeout to finish the response; clear the per-connection write deadline so the
+	// download isn't cut off mid-stream.
+	if err := http.NewResponseController(w).SetWriteDeadline(time.Time{}); err != nil {
+		log.Printf("failed to clear write deadline for job result: %v\n", err)
+	}
+
+	pdfPath, err := job.Result()
+	if err != nil {
+		switch job.Status() {
+		case jobs.StatusQueued, jobs.StatusRunning:
+			w.WriteHeader(http.StatusConflict)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+			log.Printf("job %v failed: %v\n", job.ID, err)
+		}
+		return
+	}
+
+	if reportURL := job.ReportURL(); reportURL != "" {
+		http.Redirect(w, r, reportURL, http.StatusSeeOther)
+		return
+	}
+
+	// This is the only way an un-archived job's PDF is ever handed to a client; once served,
+	// the job's own copy is no longer needed (without a cache, a re-fetch just gets a 404, same
+	// as any other once-only download; a cache-owned PDF is left in place and served again).
+	defer job.Cleanup()
+	http.ServeFile(w, r, pdfPath)
+}
+
+func (s *Server) handleLanguages(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewEncoder(w).Encode(map[string][]string{"languages": s.i18n.Supported()}); err != nil {
+		log.Printf("failed to write response: %v\n", err)
+	}
+}
+
+// handleCache handles the admin DELETE /cache endpoint, emptying the PDF cache.
+func (s *Server) handleCache(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err := s.cache.Clear(); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		log.Printf("failed to clear cache: %v\n", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Run starts the conversion HTTP server and blocks until ctx is done, the process receives an
+// interrupt/termination signal, or the server fails. It then shuts the server down gracefully.
+func (s *Server) Run(ctx context.Context) {
+	srv := &http.Server{
+		Addr:        fmt.Sprintf("%v:%v", s.cfg.Host, s.cfg.Port),
+		Handler:     middleware.SecurityHeadersMiddleware(s.securityHeaders)(s.Mux()),
+		ReadTimeout: 15 * time.Second,
+		// WriteTimeout guards ordinary request/response routes against a stalled client; the
+		// long-lived job routes (SSE events, result downloads) clear their own per-connection
+		// deadline via http.ResponseController, so this blanket timeout doesn't apply to them.
+		WriteTimeout: 15 * time.Second,
+		IdleTimeout:  60 * time.Second,
+	}
+
+	serverErr := make(chan error, 1)
+
+	go func() {
+		log.Printf("running server on %v\n", srv.Addr)
+		if err := srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- err
+		}
+	}()
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+
+	select {
+	case err := <-serverErr:
+		log.Printf("server encountered an error: %v", err)
+	case sig := <-stop:
+		log.Printf("received shutdown signal: %v", sig)
+	case <-ctx.Done():
+		log.Println("context done")
+	}
+
+	log.Println("attempting to shut down server gracefully")
+
+	// ctx may already be done here, so the shutdown grace period must not derive from it.
+	cancelCtx, cancel := context.WithTimeout(context.Background(), time.Second*15)
+	defer cancel()
+
+	if err := srv.Shutdown(cancelCtx); err != nil {
+		log.Printf("failed to shut down server: %v", err)
+	}
+}
@@ -0,0 +1,95 @@
+package server
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/LeipzigeSports/les-report-md-to-pdf/internal/config"
+	"github.com/LeipzigeSports/les-report-md-to-pdf/internal/middleware"
+)
+
+func signTeamToken(t *testing.T, secret, team string) string {
+	t.Helper()
+
+	claims := jwt.MapClaims{
+		"team": team,
+		"exp":  time.Now().Add(time.Hour).Unix(),
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	if err != nil {
+		t.Fatalf("failed to sign test token: %v", err)
+	}
+
+	return token
+}
+
+func multipartUploadBody(t *testing.T, team, mdContent string) (*bytes.Buffer, string) {
+	t.Helper()
+
+	body := &bytes.Buffer{}
+	w := multipart.NewWriter(body)
+
+	if err := w.WriteField("team", team); err != nil {
+		t.Fatalf("failed to write team field: %v", err)
+	}
+	if err := w.WriteField("md-content", mdContent); err != nil {
+		t.Fatalf("failed to write md-content field: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("failed to close multipart writer: %v", err)
+	}
+
+	return body, w.FormDataContentType()
+}
+
+// TestPostIndexRejectsTeamMismatch ensures a bearer token scoped to one team can't be used to
+// submit a report on behalf of a different team by putting a different value in the "team" form
+// field: the submitted team must match the token's own team claim.
+func TestPostIndexRejectsTeamMismatch(t *testing.T) {
+	const secret = "test-secret"
+
+	cfg := config.Config{PandocTypstTemplatePath: "/nonexistent/template.typ"}
+	s := New(cfg, nil, nil, nil, nil, "", middleware.DefaultSecurityHeaders(), true, secret)
+
+	body, contentType := multipartUploadBody(t, "team-vh", "# hello")
+
+	req := httptest.NewRequest(http.MethodPost, "/", body)
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Authorization", "Bearer "+signTeamToken(t, secret, "team-esm"))
+
+	rec := httptest.NewRecorder()
+	s.Mux().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("expected status %v for mismatched team, got %v", http.StatusForbidden, rec.Code)
+	}
+}
+
+// TestPostIndexAllowsMatchingTeam ensures the team cross-check doesn't reject a legitimately
+// scoped token, only a mismatched one.
+func TestPostIndexAllowsMatchingTeam(t *testing.T) {
+	const secret = "test-secret"
+
+	cfg := config.Config{PandocTypstTemplatePath: "/nonexistent/template.typ"}
+	s := New(cfg, nil, nil, nil, nil, "", middleware.DefaultSecurityHeaders(), true, secret)
+
+	body, contentType := multipartUploadBody(t, "team-esm", "# hello")
+
+	req := httptest.NewRequest(http.MethodPost, "/", body)
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Authorization", "Bearer "+signTeamToken(t, secret, "team-esm"))
+
+	rec := httptest.NewRecorder()
+	s.Mux().ServeHTTP(rec, req)
+
+	if rec.Code == http.StatusForbidden {
+		t.Fatalf("expected a matching team not to be rejected, got %v", rec.Code)
+	}
+}
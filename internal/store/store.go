@@ -0,0 +1,216 @@
+// Package store persists successful report conversions to disk so they can be retrieved later
+// through a short, URL-safe ID.
+package store
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const idAlphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+const idLength = 8
+
+// ErrNotFound is returned when an entry doesn't exist or has already expired.
+var ErrNotFound = errors.New("report not found")
+
+// Entry describes a single archived report.
+type Entry struct {
+	ID        string    `json:"id"`
+	Team      string    `json:"team"`
+	CreatedAt time.Time `json:"createdAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// Store persists report PDFs and their Markdown source under Dir, keyed by a random ID, and
+// expires entries after TTL.
+type Store struct {
+	Dir string
+	TTL time.Duration
+
+	mu sync.Mutex
+}
+
+// New creates a Store rooted at dir, creating the directory if it doesn't already exist.
+func New(dir string, ttl time.Duration) (*Store, error) {
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return nil, fmt.Errorf("failed to create archive directory: %w", err)
+	}
+
+	return &Store{Dir: dir, TTL: ttl}, nil
+}
+
+func generateID() (string, error) {
+	raw := make([]byte, idLength)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+
+	id := make([]byte, idLength)
+	for i, b := range raw {
+		id[i] = idAlphabet[int(b)%len(idAlphabet)]
+	}
+
+	return string(id), nil
+}
+
+func (s *Store) entryPath(id string) string  { return filepath.Join(s.Dir, id+".json") }
+func (s *Store) pdfPath(id string) string    { return filepath.Join(s.Dir, id+".pdf") }
+func (s *Store) sourcePath(id string) string { return filepath.Join(s.Dir, id+".md") }
+
+// Save writes pdf and mdSource to disk under a newly generated ID and records its metadata,
+// retrying on the unlikely event of an ID collision.
+func (s *Store) Save(team string, mdSource, pdf []byte) (Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for attempt := 0; attempt < 5; attempt++ {
+		id, err := generateID()
+		if err != nil {
+			return Entry{}, fmt.Errorf("failed to generate report id: %w", err)
+		}
+
+		if _, err := os.Stat(s.entryPath(id)); err == nil {
+			continue
+		}
+
+		now := time.Now()
+		entry := Entry{
+			ID:        id,
+			Team:      team,
+			CreatedAt: now,
+			ExpiresAt: now.Add(s.TTL),
+		}
+
+		if err := os.WriteFile(s.pdfPath(id), pdf, 0640); err != nil {
+			return Entry{}, fmt.Errorf("failed to write archived pdf: %w", err)
+		}
+
+		if err := os.WriteFile(s.sourcePath(id), mdSource, 0640); err != nil {
+			return Entry{}, fmt.Errorf("failed to write archived source: %w", err)
+		}
+
+		metaBytes, err := json.Marshal(entry)
+		if err != nil {
+			return Entry{}, fmt.Errorf("failed to marshal archive entry: %w", err)
+		}
+
+		if err := os.WriteFile(s.entryPath(id), metaBytes, 0640); err != nil {
+			return Entry{}, fmt.Errorf("failed to write archive entry: %w", err)
+		}
+
+		return entry, nil
+	}
+
+	return Entry{}, fmt.Errorf("failed to allocate a unique report id")
+}
+
+// Load returns id's entry metadata, or ErrNotFound if it doesn't exist or has expired.
+func (s *Store) Load(id string) (Entry, error) {
+	data, err := os.ReadFile(s.entryPath(id))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return Entry{}, ErrNotFound
+		}
+		return Entry{}, fmt.Errorf("failed to read archive entry: %w", err)
+	}
+
+	var entry Entry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return Entry{}, fmt.Errorf("failed to parse archive entry: %w", err)
+	}
+
+	if time.Now().After(entry.ExpiresAt) {
+		return Entry{}, ErrNotFound
+	}
+
+	return entry, nil
+}
+
+// PDFPath returns the on-disk path of id's archived PDF, after confirming the entry exists and
+// hasn't expired.
+func (s *Store) PDFPath(id string) (string, error) {
+	if _, err := s.Load(id); err != nil {
+		return "", err
+	}
+	return s.pdfPath(id), nil
+}
+
+// SourcePath returns the on-disk path of id's archived Markdown source, after confirming the
+// entry exists and hasn't expired.
+func (s *Store) SourcePath(id string) (string, error) {
+	if _, err := s.Load(id); err != nil {
+		return "", err
+	}
+	return s.sourcePath(id), nil
+}
+
+// Delete removes id's archived PDF, source, and metadata from disk.
+func (s *Store) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, p := range []string{s.pdfPath(id), s.sourcePath(id), s.entryPath(id)} {
+		if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove %v: %w", p, err)
+		}
+	}
+
+	return nil
+}
+
+// RunJanitor periodically sweeps expired entries until ctx is done. The sweep interval is
+// derived from the store's TTL, with a one-minute floor, so long-lived archives aren't swept
+// needlessly often.
+func (s *Store) RunJanitor(ctx context.Context) {
+	interval := s.TTL / 10
+	if interval < time.Minute {
+		interval = time.Minute
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	log.Printf("archive janitor running every %v\n", interval)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.sweep()
+		}
+	}
+}
+
+func (s *Store) sweep() {
+	entries, err := os.ReadDir(s.Dir)
+	if err != nil {
+		log.Printf("janitor: failed to list archive directory: %v\n", err)
+		return
+	}
+
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != ".json" {
+			continue
+		}
+
+		id := strings.TrimSuffix(e.Name(), ".json")
+
+		if _, err := s.Load(id); errors.Is(err, ErrNotFound) {
+			if err := s.Delete(id); err != nil {
+				log.Printf("janitor: failed to delete expired report %v: %v\n", id, err)
+			} else {
+				log.Printf("janitor: swept expired report %v\n", id)
+			}
+		}
+	}
+}
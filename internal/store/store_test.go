@@ -0,0 +1,164 @@
+package store
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+)
+
+func newTestStore(t *testing.T, ttl time.Duration) *Store {
+	t.Helper()
+
+	s, err := New(t.TempDir(), ttl)
+	if err != nil {
+		t.Fatalf("New returned error: %v", err)
+	}
+
+	return s
+}
+
+func TestSaveAndLoadRoundTrip(t *testing.T) {
+	s := newTestStore(t, time.Hour)
+
+	entry, err := s.Save("team-esm", []byte("# hello"), []byte("%PDF-1.4"))
+	if err != nil {
+		t.Fatalf("Save returned error: %v", err)
+	}
+
+	if entry.Team != "team-esm" {
+		t.Fatalf("expected entry.Team = team-esm, got %v", entry.Team)
+	}
+
+	loaded, err := s.Load(entry.ID)
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+
+	if loaded.ID != entry.ID || loaded.Team != entry.Team {
+		t.Fatalf("Load returned a different entry: %+v, want %+v", loaded, entry)
+	}
+
+	pdfPath, err := s.PDFPath(entry.ID)
+	if err != nil {
+		t.Fatalf("PDFPath returned error: %v", err)
+	}
+	pdfBytes, err := os.ReadFile(pdfPath)
+	if err != nil {
+		t.Fatalf("failed to read archived pdf: %v", err)
+	}
+	if string(pdfBytes) != "%PDF-1.4" {
+		t.Fatalf("archived pdf content = %q, want %q", pdfBytes, "%PDF-1.4")
+	}
+
+	sourcePath, err := s.SourcePath(entry.ID)
+	if err != nil {
+		t.Fatalf("SourcePath returned error: %v", err)
+	}
+	sourceBytes, err := os.ReadFile(sourcePath)
+	if err != nil {
+		t.Fatalf("failed to read archived source: %v", err)
+	}
+	if string(sourceBytes) != "# hello" {
+		t.Fatalf("archived source content = %q, want %q", sourceBytes, "# hello")
+	}
+}
+
+func TestLoadReturnsErrNotFoundForUnknownID(t *testing.T) {
+	s := newTestStore(t, time.Hour)
+
+	if _, err := s.Load("nonexistent"); err != ErrNotFound {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestLoadReturnsErrNotFoundForExpiredEntry(t *testing.T) {
+	s := newTestStore(t, -time.Minute)
+
+	entry, err := s.Save("team-esm", []byte("# hello"), []byte("%PDF-1.4"))
+	if err != nil {
+		t.Fatalf("Save returned error: %v", err)
+	}
+
+	if _, err := s.Load(entry.ID); err != ErrNotFound {
+		t.Fatalf("expected ErrNotFound for an already-expired entry, got %v", err)
+	}
+
+	if _, err := s.PDFPath(entry.ID); err != ErrNotFound {
+		t.Fatalf("expected PDFPath to also reject an expired entry, got %v", err)
+	}
+}
+
+func TestDeleteRemovesAllFiles(t *testing.T) {
+	s := newTestStore(t, time.Hour)
+
+	entry, err := s.Save("team-esm", []byte("# hello"), []byte("%PDF-1.4"))
+	if err != nil {
+		t.Fatalf("Save returned error: %v", err)
+	}
+
+	if err := s.Delete(entry.ID); err != nil {
+		t.Fatalf("Delete returned error: %v", err)
+	}
+
+	if _, err := s.Load(entry.ID); err != ErrNotFound {
+		t.Fatalf("expected ErrNotFound after Delete, got %v", err)
+	}
+
+	for _, p := range []string{s.pdfPath(entry.ID), s.sourcePath(entry.ID), s.entryPath(entry.ID)} {
+		if _, err := os.Stat(p); !os.IsNotExist(err) {
+			t.Fatalf("expected %v to be removed, stat err = %v", p, err)
+		}
+	}
+
+	// Deleting an already-deleted entry is a no-op, not an error.
+	if err := s.Delete(entry.ID); err != nil {
+		t.Fatalf("Delete on an already-deleted entry returned error: %v", err)
+	}
+}
+
+func TestSweepRemovesExpiredEntriesOnly(t *testing.T) {
+	s := newTestStore(t, time.Hour)
+
+	fresh, err := s.Save("team-esm", []byte("# fresh"), []byte("%PDF-1.4"))
+	if err != nil {
+		t.Fatalf("Save returned error: %v", err)
+	}
+
+	stale, err := s.Save("team-vh", []byte("# stale"), []byte("%PDF-1.4"))
+	if err != nil {
+		t.Fatalf("Save returned error: %v", err)
+	}
+
+	// Backdate the stale entry's metadata file so its TTL has already elapsed, without
+	// disturbing the fresh entry.
+	staleEntry, err := s.Load(stale.ID)
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	staleEntry.ExpiresAt = time.Now().Add(-time.Minute)
+	rewriteEntry(t, s, staleEntry)
+
+	s.sweep()
+
+	if _, err := s.Load(fresh.ID); err != nil {
+		t.Fatalf("expected fresh entry to survive sweep, got %v", err)
+	}
+
+	if _, err := os.Stat(s.entryPath(stale.ID)); !os.IsNotExist(err) {
+		t.Fatalf("expected stale entry to be swept from disk, stat err = %v", err)
+	}
+}
+
+func rewriteEntry(t *testing.T, s *Store, entry Entry) {
+	t.Helper()
+
+	metaBytes, err := json.Marshal(entry)
+	if err != nil {
+		t.Fatalf("failed to marshal entry: %v", err)
+	}
+
+	if err := os.WriteFile(s.entryPath(entry.ID), metaBytes, 0640); err != nil {
+		t.Fatalf("failed to rewrite entry: %v", err)
+	}
+}